@@ -1,7 +0,0 @@
-package main
-
-import "testing"
-
-func BenchmarkApp(b *testing.B) {
-	mainLocal()
-}
@@ -0,0 +1,461 @@
+package board
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+/**
+ *  	The Goal
+The goal of the game is to block the opponent's pawn.
+
+ 	Rules
+Board:
+The game is played on a 9 x 9 boardRemoved.
+
+Player 0 always starts at (0, 4) and player 1 at (8, 4).
+(other dimensions and start squares can be studied with SetConfig, and variants of the rules with SetRules)
+
+At each turn:
+You must move your pawn to an adjacent tile (diagonal included) :
+
+You can't :
+stay put
+move to the tile occupied by the opponent's pawn
+move to an already removed tile.
+Then you must remove a free tile.
+You can't remove a tile occupied by a pawn.
+
+Victory Conditions
+At his turn, the opponent can't move his pawn.
+
+Loss Conditions
+At your turn, you can't move your pawn.
+You do not respond in time or output an invalid action.
+
+ 	Game Input
+Initialization input
+Line 1: playerPositionX
+Line 2: playerPositionY the coordinates of your pawn.
+
+Input for one game turn
+Line 1: opponentPositionX
+Line 2: opponentPositionY the coordinates of the opponent's pawn.
+Line 3: opponentLastRemovedTileX
+Line 4: opponentLastRemovedTileY the coordinates of the last tile removed by the opponent ( -1 -1 if no tile has been removed (first round)).
+
+Output
+A single line containing the coordinates where you want to move your pawn, followed by the coordinates of the tile you want to remove.
+Example: 1 4 7 4
+
+You can also add a message :
+Example: 1 4 7 4;MESSAGE
+NB : You can print RANDOM instead of the 4 coordinates. Then a random possible move and tile will be chosen.
+
+Constraints
+Response time first turn is ≤ 1000 ms.
+Response time per turn is ≤ 100 ms.
+ **/
+
+type Coord struct {
+	X uint8
+	Y uint8
+}
+
+func (c Coord) String() string {
+	return fmt.Sprintf("(%d, %d)", c.X, c.Y)
+}
+
+type State struct {
+	// the first PlayersCount positions are used
+	PlayersPosition [MAX_PLAYERS]Coord
+	BoardRemoved    CompactBoolArray
+	Turn            uint8
+	// the player who plays the next action, player 0 plays first
+	PlayerToMove uint8
+	// bit p is set when player p is eliminated, its pawn stays on its tile
+	EliminatedPlayers uint8
+}
+
+/**
+ * A compact boolean array of MAX_GRID_SIZE bits, indexed by TileIndex so that its layout doesn't depend on the board configuration.
+ */
+type CompactBoolArray struct {
+	parts [MAX_GRID_SIZE / 64]uint64
+}
+
+func (c *CompactBoolArray) Set(index uint8, value bool) {
+	if value {
+		c.parts[index/64] |= 1 << (index % 64)
+	} else {
+		c.parts[index/64] &= ^(1 << (index % 64))
+	}
+}
+
+func (c *CompactBoolArray) Get(index uint8) bool {
+	return (c.parts[index/64] & (1 << (index % 64))) != 0
+}
+
+func (c *CompactBoolArray) Count() int {
+	count := 0
+	for _, part := range c.parts {
+		count += bits.OnesCount64(part)
+	}
+	return count
+}
+
+func (c *CompactBoolArray) Show() string {
+	var result strings.Builder
+	for y := uint8(0); y < Height; y++ {
+		for x := uint8(0); x < Width; x++ {
+			if c.Get(TileIndex(Coord{x, y})) {
+				result.WriteString("X")
+			} else {
+				result.WriteString(".")
+			}
+		}
+	}
+	return result.String()
+}
+
+type Action struct {
+	MovePosition Coord
+	RemoveTile   Coord
+	// only with two removals per turn, see GameRules. The move generator puts it after RemoveTile on the board
+	SecondRemoveTile Coord
+}
+
+/**
+ * Parses an action in the output format "x y x y", followed by the second removed tile "x y" with two removals per turn.
+ * An optional ";MESSAGE" suffix is ignored.
+ */
+func ParseAction(s string) (Action, error) {
+	fields := strings.Fields(strings.SplitN(s, ";", 2)[0])
+
+	coordinatesCount := 2 + 2*int(GameRules.Removals)
+	if len(fields) != coordinatesCount {
+		return Action{}, fmt.Errorf("expected %d coordinates in %q", coordinatesCount, s)
+	}
+
+	var values [2 + 2*MAX_REMOVALS]uint8
+
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+
+		limit := int(Width)
+		if i%2 == 1 {
+			limit = int(Height)
+		}
+
+		if err != nil || value < 0 || value >= limit {
+			return Action{}, fmt.Errorf("invalid coordinate %q in %q", field, s)
+		}
+
+		values[i] = uint8(value)
+	}
+
+	return Action{Coord{values[0], values[1]}, Coord{values[2], values[3]}, Coord{values[4], values[5]}}, nil
+}
+
+func FormatAction(a *Action) string {
+	if GameRules.Removals == 2 {
+		return fmt.Sprintf("%d %d %d %d %d %d", a.MovePosition.X, a.MovePosition.Y, a.RemoveTile.X, a.RemoveTile.Y, a.SecondRemoveTile.X, a.SecondRemoveTile.Y)
+	}
+	return fmt.Sprintf("%d %d %d %d", a.MovePosition.X, a.MovePosition.Y, a.RemoveTile.X, a.RemoveTile.Y)
+}
+
+/**
+ * The pawns on their start squares and the blocked tiles of the rules removed.
+ */
+func InitialState() State {
+	currentState := State{
+		PlayersPosition: StartPositions,
+		BoardRemoved:    CompactBoolArray{},
+		Turn:            0,
+	}
+
+	for _, tile := range GameRules.BlockedTiles {
+		currentState.BoardRemoved.Set(TileIndex(tile), true)
+	}
+
+	return currentState
+}
+
+func GetActionIndex(actions []Action, a Action) int {
+	for i := range actions {
+		if actions[i] == a {
+			return i
+		}
+	}
+	return -1
+}
+
+// a tile is removed at each ply or a player is eliminated, so a game can't be longer than this
+const MAX_PLIES = MAX_GRID_SIZE + MAX_PLAYERS
+
+/**
+ * The actions generated at each ply of a depth-first search, a local slice would be allocated at each node.
+ * The actions of a ply grow to the largest number of actions generated at this ply, then they are reused.
+ */
+type ActionsStack [MAX_PLIES + 1][]Action
+
+/**
+ * The empty actions of the ply, to generate its actions into.
+ */
+func (stack *ActionsStack) Reuse(ply int) []Action {
+	return stack[ply][:0]
+}
+
+/**
+ * Keeps the actions generated at the ply for the next Reuse, and returns them.
+ */
+func (stack *ActionsStack) Keep(ply int, actions []Action) []Action {
+	stack[ply] = actions
+	return actions
+}
+
+func ApplyMove(currentState *State, movePosition Coord, playerId uint8) State {
+	nextState := *currentState
+	nextState.PlayersPosition[playerId] = movePosition
+	return nextState
+}
+
+/**
+ * Plays the action for the player to move, then it is the turn of the next player who is not eliminated.
+ * The resulting state doesn't depend on the order of the move and the removals, only their legality does.
+ */
+func ApplyAction(state *State, action *Action) State {
+	nextState := ApplyMove(state, action.MovePosition, state.PlayerToMove)
+	nextState.BoardRemoved.Set(TileIndex(action.RemoveTile), true)
+	if GameRules.Removals == 2 {
+		nextState.BoardRemoved.Set(TileIndex(action.SecondRemoveTile), true)
+	}
+	nextState.Turn++
+	nextState.PlayerToMove = GetNextPlayer(&nextState, nextState.PlayerToMove)
+	return nextState
+}
+
+/**
+ * With more than two players, the player to move who can't play is eliminated and it is the turn of the next player.
+ * With two players, or when a single other player is left, the game is over instead.
+ */
+func EliminatePlayer(state *State) State {
+	nextState := *state
+	nextState.EliminatedPlayers |= 1 << nextState.PlayerToMove
+	nextState.PlayerToMove = GetNextPlayer(&nextState, nextState.PlayerToMove)
+	return nextState
+}
+
+/**
+ * The next player after playerId in the order of the turns who is not eliminated.
+ */
+func GetNextPlayer(state *State, playerId uint8) uint8 {
+	for i := uint8(1); i < PlayersCount; i++ {
+		nextPlayerId := (playerId + i) % PlayersCount
+		if !IsEliminated(state, nextPlayerId) {
+			return nextPlayerId
+		}
+	}
+	return playerId
+}
+
+func IsEliminated(state *State, playerId uint8) bool {
+	return state.EliminatedPlayers&(1<<playerId) != 0
+}
+
+func GetActivePlayersCount(state *State) int {
+	return int(PlayersCount) - bits.OnesCount8(state.EliminatedPlayers)
+}
+
+func distance(coord1 Coord, coord2 Coord) int {
+	return int(math.Abs(float64(coord1.X-coord2.X)) + math.Abs(float64(coord1.Y-coord2.Y)))
+}
+
+//func getPossibleRemoves(currentState state, myPlayerId int) (possibleRemoves []coord) {
+//	// a player can remove any tile that is not occupied by a pawn and not already removed
+//	for y := 0; y < HEIGHT; y++ {
+//		for x := 0; x < WIDTH; x++ {
+//			if !isTileOccupied(currentState, coord{x, y}) && !isTileRemoved(currentState, coord{x, y}) {
+//				possibleRemoves = append(possibleRemoves, coord{x, y})
+//			}
+//		}
+//	}
+//
+//	return
+//}
+
+//func getPossibleRemoves(currentState state, possibleRemoves *[]coord) {
+//
+//	// empty possible removes
+//	*possibleRemoves = (*possibleRemoves)[:0]
+//
+//	// a player can remove any tile that is not occupied by a pawn and not already removed
+//	for y := 0; y < HEIGHT; y++ {
+//		for x := 0; x < WIDTH; x++ {
+//			c := coord{x, y}
+//			if !isTileOccupied(currentState, c) && !isTileRemoved(currentState, c) {
+//				*possibleRemoves = append(*possibleRemoves, c)
+//			}
+//		}
+//	}
+//}
+
+var cacheAdjacentTiles = make([][]Coord, MAX_GRID_SIZE)
+var cacheTiles = make([]Coord, 0, MAX_GRID_SIZE)
+
+/**
+ * Fills the adjacent tiles of each tile of the board, the tiles a pawn can move to with the moves of GameRules, and the symmetries of the board.
+ * SetConfig and SetRules call it again when the board or the rules change.
+ */
+func InitAdjacentTilesCache() {
+	cacheTiles = cacheTiles[:0]
+
+	for y := uint8(0); y < Height; y++ {
+		for x := uint8(0); x < Width; x++ {
+			position := Coord{x, y}
+
+			adjacentTiles := make([]Coord, 0, MAX_MOVES)
+
+			for _, offset := range moveOffsets[GameRules.Moves] {
+				// outside of the board, a negative coordinate wraps to a large one
+				c := Coord{uint8(int(x) + offset[0]), uint8(int(y) + offset[1])}
+				if IsOnBoard(c) {
+					adjacentTiles = append(adjacentTiles, c)
+				}
+			}
+
+			cacheAdjacentTiles[TileIndex(position)] = adjacentTiles
+			cacheTiles = append(cacheTiles, position)
+		}
+	}
+
+	initSymmetries()
+}
+
+/**
+ * All the tiles of the board, row by row.
+ */
+func GetTiles() []Coord {
+	return cacheTiles
+}
+
+func GetAdjacentTiles(position Coord) (adjacentTiles *[]Coord) {
+	return &(cacheAdjacentTiles[TileIndex(position)])
+}
+
+/**
+ * A tile is occupied by a pawn, eliminated or not.
+ */
+func IsTileOccupied(currentState *State, position *Coord) bool {
+	if currentState.PlayersPosition[0] == *position || currentState.PlayersPosition[1] == *position {
+		return true
+	}
+	for playerId := uint8(2); playerId < PlayersCount; playerId++ {
+		if currentState.PlayersPosition[playerId] == *position {
+			return true
+		}
+	}
+	return false
+}
+
+func IsTileRemoved(currentState *State, position *Coord) bool {
+	return currentState.BoardRemoved.Get(TileIndex(*position))
+}
+
+/**
+ * A free tile can be moved onto or removed.
+ */
+func IsTileFree(currentState *State, position *Coord) bool {
+	return !IsTileOccupied(currentState, position) && !IsTileRemoved(currentState, position)
+}
+
+func IsOnBoard(position Coord) bool {
+	return position.X < Width && position.Y < Height
+}
+
+/**
+ * Returns why the action is not allowed by GameRules for playerId, nil if it is legal.
+ * Any free tile can be removed, movegen.GetPossibleActions only generates a subset of the legal removals unless movegen.AllowNotNeighbor is set.
+ */
+func ValidateAction(currentState *State, a *Action, playerId uint8) error {
+	if GameRules.RemoveFirst {
+		if err := validateRemovals(currentState, a); err != nil {
+			return err
+		}
+
+		removedState := *currentState
+		for _, tile := range GetRemovedTiles(a) {
+			removedState.BoardRemoved.Set(TileIndex(tile), true)
+		}
+
+		return validateMove(&removedState, a, playerId)
+	}
+
+	if err := validateMove(currentState, a, playerId); err != nil {
+		return err
+	}
+
+	nextState := ApplyMove(currentState, a.MovePosition, playerId)
+
+	return validateRemovals(&nextState, a)
+}
+
+func validateMove(currentState *State, a *Action, playerId uint8) error {
+	myPosition := currentState.PlayersPosition[playerId]
+
+	switch {
+	case !IsOnBoard(a.MovePosition):
+		return fmt.Errorf("can't move to %v: outside of the board", a.MovePosition)
+	case a.MovePosition == myPosition:
+		return fmt.Errorf("can't move to %v: the pawn can't stay put", a.MovePosition)
+	case !Contains(*GetAdjacentTiles(myPosition), a.MovePosition):
+		return fmt.Errorf("can't move to %v: not adjacent to the pawn at %v with %s moves", a.MovePosition, myPosition, moveKindNames[GameRules.Moves])
+	case IsTileOccupied(currentState, &a.MovePosition):
+		return fmt.Errorf("can't move to %v: occupied by the opponent's pawn", a.MovePosition)
+	case IsTileRemoved(currentState, &a.MovePosition):
+		return fmt.Errorf("can't move to %v: the tile is removed", a.MovePosition)
+	}
+
+	return nil
+}
+
+func validateRemovals(currentState *State, a *Action) error {
+	removedTiles := GetRemovedTiles(a)
+
+	for i := range removedTiles {
+		tile := &removedTiles[i]
+
+		switch {
+		case !IsOnBoard(*tile):
+			return fmt.Errorf("can't remove %v: outside of the board", *tile)
+		case IsTileOccupied(currentState, tile):
+			return fmt.Errorf("can't remove %v: occupied by a pawn", *tile)
+		case IsTileRemoved(currentState, tile) || Contains(removedTiles[:i], *tile):
+			return fmt.Errorf("can't remove %v: the tile is already removed", *tile)
+		}
+	}
+
+	return nil
+}
+
+func Contains(slice []Coord, element Coord) bool {
+	// fastest way to check if a slice contains an element
+	for i := 0; i < len(slice); i++ {
+		if slice[i] == element {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Two different tiles are next to each other when they touch, diagonals included.
+ */
+func IsNextTo(a Coord, b Coord) bool {
+	dx := int(a.X) - int(b.X)
+	dy := int(a.Y) - int(b.Y)
+	return a != b && dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+}
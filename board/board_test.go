@@ -0,0 +1,299 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdjacentTiles(t *testing.T) {
+	InitAdjacentTilesCache()
+
+	tests := []struct {
+		name     string
+		position Coord
+		want     int
+	}{
+		{"top left corner", Coord{0, 0}, 3},
+		{"top right corner", Coord{8, 0}, 3},
+		{"bottom left corner", Coord{0, 8}, 3},
+		{"bottom right corner", Coord{8, 8}, 3},
+		{"top edge", Coord{4, 0}, 5},
+		{"left edge", Coord{0, 4}, 5},
+		{"right edge", Coord{8, 4}, 5},
+		{"bottom edge", Coord{4, 8}, 5},
+		{"next to a corner", Coord{1, 1}, 8},
+		{"center", Coord{4, 4}, 8},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			adjacentTiles := *GetAdjacentTiles(test.position)
+
+			if len(adjacentTiles) != test.want {
+				t.Errorf("%d adjacent tiles, want %d: %v", len(adjacentTiles), test.want, adjacentTiles)
+			}
+
+			for _, adjacentTile := range adjacentTiles {
+				if !IsOnBoard(adjacentTile) || !IsNextTo(adjacentTile, test.position) {
+					t.Errorf("%v is not adjacent to %v", adjacentTile, test.position)
+				}
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	defer SetConfig(DEFAULT_CONFIG)
+
+	tests := []struct {
+		notation string
+		want     string
+		// part of the error message, empty for a valid board
+		wantErr string
+	}{
+		{"9x9", "9x9 0,4 8,4", ""},
+		{"9x9 0,4 8,4", "9x9 0,4 8,4", ""},
+		{"5x4", "5x4 0,2 4,2", ""},
+		{"16x16 0,0 15,15", "16x16 0,0 15,15", ""},
+		{"17x9", "", "between 1 and 16x16"},
+		{"0x9", "", "invalid dimensions"},
+		{"9", "", "invalid dimensions"},
+		{"9x9 0,4", "", "expected the dimensions"},
+		{"9x9 0,4 9,4", "", "outside of the 9x9 board"},
+		{"9x9 0,4 0,4", "", "players 0 and 1 start at"},
+		{"7x7 players=4", "7x7 0,3 6,3 3,0 3,6", ""},
+		{"7x7 players=3", "7x7 0,3 6,3 3,0", ""},
+		{"7x7 0,0 6,6 0,6", "7x7 0,0 6,6 0,6", ""},
+		{"7x7 players=5", "", "invalid number of players 5"},
+		{"7x7 0,0 6,6 0,6 6,0 3,3", "", "expected the dimensions"},
+		{"7x7 0,0 6,6 6,6", "", "players 1 and 2 start at"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.notation, func(t *testing.T) {
+			config, err := ParseConfig(test.notation)
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FormatConfig(config); got != test.want {
+				t.Fatalf("FormatConfig = %q, want %q", got, test.want)
+			}
+
+			if err := SetConfig(config); err != nil {
+				t.Fatal(err)
+			}
+
+			// every tile of the board, including the ones after the 96th, can be removed and hashed on its own
+			initial := InitialState()
+			hashes := map[uint64]Coord{HashState(&initial): {}}
+			removedCount := 0
+
+			for y := uint8(0); y < Height; y++ {
+				for x := uint8(0); x < Width; x++ {
+					c := Coord{x, y}
+					if IsTileOccupied(&initial, &c) {
+						continue
+					}
+
+					removedState := initial
+					removedState.BoardRemoved.Set(TileIndex(c), true)
+					removedCount++
+
+					if !IsTileRemoved(&removedState, &c) || removedState.BoardRemoved.Count() != 1 {
+						t.Fatalf("removing %v removes %s", c, removedState.BoardRemoved.Show())
+					}
+					if previous, ok := hashes[HashState(&removedState)]; ok {
+						t.Fatalf("removing %v and %v have the same hash", c, previous)
+					}
+					hashes[HashState(&removedState)] = c
+				}
+			}
+
+			if removedCount != GridSize-int(PlayersCount) {
+				t.Fatalf("%d tiles can be removed, want %d", removedCount, GridSize-int(PlayersCount))
+			}
+
+			parsedState, err := ParsePosition(FormatPosition(&initial))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsedState != initial {
+				t.Fatalf("ParsePosition(%q) = %v, want %v", FormatPosition(&initial), parsedState, initial)
+			}
+
+			bottomRight := Coord{Width - 1, Height - 1}
+			if len(*GetAdjacentTiles(bottomRight)) != 3 {
+				t.Fatalf("%v has %d adjacent tiles, want 3", bottomRight, len(*GetAdjacentTiles(bottomRight)))
+			}
+		})
+	}
+}
+
+func TestRulesNotation(t *testing.T) {
+	tests := []struct {
+		notation string
+		want     string
+		// part of the error message, empty for valid rules
+		wantErr string
+	}{
+		{"", "king", ""},
+		{"king", "king", ""},
+		{"removals=2 knight", "knight removals=2", ""},
+		{"orthogonal remove-first blocked=4,4 blocked=2,3", "orthogonal remove-first blocked=4,4 blocked=2,3", ""},
+		{"removals=3", "", "between 1 and 2"},
+		{"blocked=0,4", "", "is a start square"},
+		{"blocked=9,0", "", "outside of the 9x9 board"},
+		{"bishop", "", "unknown rule"},
+		{"blocked=4", "", "invalid blocked tile"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.notation, func(t *testing.T) {
+			rules, err := ParseRules(test.notation)
+			if err == nil {
+				err = rules.Validate(DEFAULT_CONFIG)
+			}
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FormatRules(rules); got != test.want {
+				t.Fatalf("FormatRules = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEliminatePlayer(t *testing.T) {
+	defer SetConfig(DEFAULT_CONFIG)
+
+	if err := SetConfig(NewConfigWithPlayers(5, 5, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// C is walled in the middle of the first row
+	currentState, err := ParsePosition(".#C#./.###./A...B/...../..... 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eliminatedState := EliminatePlayer(&currentState)
+
+	if got, want := FormatPosition(&eliminatedState), ".#c#./.###./A...B/...../..... 0"; got != want {
+		t.Fatalf("FormatPosition = %q, want %q", got, want)
+	}
+	if GetActivePlayersCount(&eliminatedState) != 2 || HashState(&eliminatedState) == HashState(&currentState) {
+		t.Fatalf("eliminating player 2 gives %d active players and hash %x", GetActivePlayersCount(&eliminatedState), HashState(&eliminatedState))
+	}
+
+	// the eliminated pawn stays on its tile and is skipped by the turns
+	a := Action{MovePosition: Coord{4, 1}, RemoveTile: Coord{4, 0}}
+	if err := ValidateAction(&eliminatedState, &Action{MovePosition: Coord{1, 2}, RemoveTile: Coord{2, 0}}, 0); err == nil {
+		t.Fatal("removing the tile of the eliminated pawn is legal")
+	}
+	nextState := ApplyAction(&eliminatedState, &Action{MovePosition: Coord{1, 2}, RemoveTile: Coord{0, 0}})
+	if nextState.PlayerToMove != 1 {
+		t.Fatalf("player %d to move after player 0, want 1", nextState.PlayerToMove)
+	}
+	nextState = ApplyAction(&nextState, &a)
+	if nextState.PlayerToMove != 0 {
+		t.Fatalf("player %d to move after player 1, want 0", nextState.PlayerToMove)
+	}
+
+	parsedState, err := ParsePosition(FormatPosition(&nextState))
+	if err != nil || parsedState != nextState {
+		t.Fatalf("ParsePosition(%q) = %v, %v, want %v", FormatPosition(&nextState), parsedState, err, nextState)
+	}
+
+	if _, err := ParsePosition(".#c#./.###./A...B/...../..... 2"); err == nil {
+		t.Fatal("an eliminated player to move is parsed")
+	}
+}
+
+func TestSymmetries(t *testing.T) {
+	defer SetConfig(DEFAULT_CONFIG)
+
+	if err := SetConfig(NewConfigWithPlayers(5, 5, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if len(GetSymmetries()) != int(SYMMETRIES_COUNT) {
+		t.Fatalf("symmetries of 5x5: %v", GetSymmetries())
+	}
+
+	currentState, err := ParsePosition("#..../..#../A...B/...../.#... 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mirroredState := TransformState(&currentState, SYMMETRY_MIRROR)
+	if got, want := FormatPosition(&mirroredState), ".#.../...../A...B/..#../#.... 1"; got != want {
+		t.Fatalf("mirror: FormatPosition = %q, want %q", got, want)
+	}
+	rotatedState := TransformState(&currentState, SYMMETRY_ROTATION)
+	if got, want := FormatPosition(&rotatedState), "...#./...../A...B/..#../....# 0"; got != want {
+		t.Fatalf("rotation: FormatPosition = %q, want %q", got, want)
+	}
+
+	canonicalHash, _ := GetCanonicalHash(&currentState)
+	canonicalState, _ := CanonicalizeState(&currentState)
+	a := Action{MovePosition: Coord{3, 1}, RemoveTile: Coord{1, 3}}
+	nextState := ApplyAction(&currentState, &a)
+
+	for _, symmetry := range GetSymmetries() {
+		transformedState := TransformState(&currentState, symmetry)
+
+		if twiceTransformedState := TransformState(&transformedState, symmetry); twiceTransformedState != currentState {
+			t.Fatalf("%v twice gives %s, want %s", symmetry, FormatPosition(&twiceTransformedState), FormatPosition(&currentState))
+		}
+		if hashTransformedState(&currentState, symmetry) != HashState(&transformedState) {
+			t.Fatalf("%v: the hash of the transformed state differs", symmetry)
+		}
+
+		// the symmetric states share their representative
+		if hash, _ := GetCanonicalHash(&transformedState); hash != canonicalHash {
+			t.Fatalf("%v: canonical hash %x, want %x", symmetry, hash, canonicalHash)
+		}
+		if representative, _ := CanonicalizeState(&transformedState); representative != canonicalState {
+			t.Fatalf("%v: representative %s, want %s", symmetry, FormatPosition(&representative), FormatPosition(&canonicalState))
+		}
+
+		// the transformed action plays the transformed game
+		transformedAction := TransformAction(&a, symmetry)
+		if err := ValidateAction(&transformedState, &transformedAction, transformedState.PlayerToMove); err != nil {
+			t.Fatalf("%v: %v", symmetry, err)
+		}
+		if transformedNextState := ApplyAction(&transformedState, &transformedAction); TransformState(&nextState, symmetry) != transformedNextState {
+			t.Fatalf("%v: %s transformed to %s", symmetry, FormatAction(&a), FormatAction(&transformedAction))
+		}
+	}
+
+	if err := SetConfig(Config{Width: 5, Height: 5, PlayersCount: 2, StartPositions: [MAX_PLAYERS]Coord{{0, 1}, {4, 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(GetSymmetries()) != 1 {
+		t.Fatalf("symmetries of start squares off the middle row: %v", GetSymmetries())
+	}
+
+	// the players aren't swapped in the games of more than two players
+	if err := SetConfig(Config{Width: 5, Height: 5, PlayersCount: 3, StartPositions: [MAX_PLAYERS]Coord{{0, 2}, {4, 2}, {2, 2}}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(GetSymmetries()) != 2 || GetSymmetries()[1] != SYMMETRY_MIRROR {
+		t.Fatalf("symmetries with 3 players: %v", GetSymmetries())
+	}
+}
@@ -0,0 +1,195 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the largest board, the tiles of a state are stored in a fixed-size bitset of MAX_GRID_SIZE bits
+const MAX_WIDTH = 16
+const MAX_HEIGHT = 16
+
+const MAX_GRID_SIZE = MAX_WIDTH * MAX_HEIGHT
+
+const MAX_PLAYERS = 4
+
+/**
+ * The dimensions of the board and the start square of each player, they play in the order of their ids.
+ */
+type Config struct {
+	Width          uint8
+	Height         uint8
+	PlayersCount   uint8
+	StartPositions [MAX_PLAYERS]Coord
+}
+
+/**
+ * A width x height board where player 0 starts in the middle of the first column and player 1 in the middle of the last one.
+ */
+func NewConfig(width uint8, height uint8) Config {
+	return NewConfigWithPlayers(width, height, 2)
+}
+
+/**
+ * A width x height board for 2 to MAX_PLAYERS players: players 0 and 1 start like in NewConfig,
+ * player 2 in the middle of the first row and player 3 in the middle of the last one.
+ */
+func NewConfigWithPlayers(width uint8, height uint8, playersCount uint8) Config {
+	config := Config{Width: width, Height: height, PlayersCount: playersCount}
+	startPositions := [MAX_PLAYERS]Coord{{0, height / 2}, {width - 1, height / 2}, {width / 2, 0}, {width / 2, height - 1}}
+
+	for playerId := uint8(0); playerId < playersCount && playerId < MAX_PLAYERS; playerId++ {
+		config.StartPositions[playerId] = startPositions[playerId]
+	}
+
+	return config
+}
+
+// the board of CodinGame: 9 x 9, player 0 starts at (0, 4) and player 1 at (8, 4)
+var DEFAULT_CONFIG = NewConfig(9, 9)
+
+// the board being played, set by SetConfig
+var Width = DEFAULT_CONFIG.Width
+var Height = DEFAULT_CONFIG.Height
+var GridSize = int(Width) * int(Height)
+var PlayersCount = DEFAULT_CONFIG.PlayersCount
+var StartPositions = DEFAULT_CONFIG.StartPositions
+
+/**
+ * Plays the following games on the board of config. The states of the previous board must not be used anymore.
+ */
+func SetConfig(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if err := GameRules.Validate(config); err != nil {
+		return err
+	}
+
+	Width = config.Width
+	Height = config.Height
+	GridSize = int(Width) * int(Height)
+	PlayersCount = config.PlayersCount
+	StartPositions = config.StartPositions
+
+	for i := range cacheAdjacentTiles {
+		cacheAdjacentTiles[i] = nil
+	}
+	InitAdjacentTilesCache()
+
+	return nil
+}
+
+func GetConfig() Config {
+	return Config{Width, Height, PlayersCount, StartPositions}
+}
+
+func (config Config) Validate() error {
+	if config.Width < 1 || config.Width > MAX_WIDTH || config.Height < 1 || config.Height > MAX_HEIGHT {
+		return fmt.Errorf("invalid board %dx%d: the dimensions must be between 1 and %dx%d", config.Width, config.Height, MAX_WIDTH, MAX_HEIGHT)
+	}
+
+	if config.PlayersCount < 2 || config.PlayersCount > MAX_PLAYERS {
+		return fmt.Errorf("invalid number of players %d: it must be between 2 and %d", config.PlayersCount, MAX_PLAYERS)
+	}
+
+	for playerId, position := range config.StartPositions[:config.PlayersCount] {
+		if position.X >= config.Width || position.Y >= config.Height {
+			return fmt.Errorf("the start square %v of player %d is outside of the %dx%d board", position, playerId, config.Width, config.Height)
+		}
+		for otherPlayerId := 0; otherPlayerId < playerId; otherPlayerId++ {
+			if config.StartPositions[otherPlayerId] == position {
+				return fmt.Errorf("players %d and %d start at %v", otherPlayerId, playerId, position)
+			}
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Board notation: the dimensions followed by the start square of each player, for example "9x9 0,4 8,4".
+ */
+func FormatConfig(config Config) string {
+	var result strings.Builder
+
+	fmt.Fprintf(&result, "%dx%d", config.Width, config.Height)
+	for _, position := range config.StartPositions[:config.PlayersCount] {
+		fmt.Fprintf(&result, " %d,%d", position.X, position.Y)
+	}
+
+	return result.String()
+}
+
+/**
+ * Parses a board written by FormatConfig, the start squares are optional: "7x7" is NewConfig(7, 7),
+ * and "7x7 players=4" is NewConfigWithPlayers(7, 7, 4).
+ */
+func ParseConfig(s string) (Config, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 1+MAX_PLAYERS {
+		return Config{}, fmt.Errorf("expected the dimensions and optionally the number of players or the start squares in %q", s)
+	}
+
+	width, height, err := parsePair(fields[0], "x")
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid dimensions in %q: %w", s, err)
+	}
+	if width < 1 || height < 1 {
+		return Config{}, fmt.Errorf("invalid dimensions in %q", s)
+	}
+
+	config := NewConfig(width, height)
+
+	if len(fields) == 2 && strings.HasPrefix(fields[1], "players=") {
+		playersCount, err := strconv.ParseUint(strings.TrimPrefix(fields[1], "players="), 10, 8)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid number of players in %q", s)
+		}
+		config = NewConfigWithPlayers(width, height, uint8(playersCount))
+		return config, config.Validate()
+	}
+
+	if len(fields) == 2 {
+		return Config{}, fmt.Errorf("expected the dimensions and the start squares of at least 2 players in %q", s)
+	}
+	if len(fields) > 2 {
+		config = Config{Width: width, Height: height, PlayersCount: uint8(len(fields) - 1)}
+	}
+
+	for playerId, field := range fields[1:] {
+		x, y, err := parsePair(field, ",")
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid start square of player %d in %q: %w", playerId, s, err)
+		}
+		config.StartPositions[playerId] = Coord{x, y}
+	}
+
+	return config, config.Validate()
+}
+
+func parsePair(s string, separator string) (uint8, uint8, error) {
+	parts := strings.Split(s, separator)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected two numbers separated by %q in %q", separator, s)
+	}
+
+	var values [2]uint8
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid number %q", part)
+		}
+		values[i] = uint8(value)
+	}
+
+	return values[0], values[1], nil
+}
+
+/**
+ * The index of a tile in CompactBoolArray and in the arrays of MAX_GRID_SIZE tiles, rows are MAX_WIDTH apart whatever the width of the board.
+ */
+func TileIndex(position Coord) uint8 {
+	return position.Y*MAX_WIDTH + position.X
+}
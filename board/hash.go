@@ -0,0 +1,65 @@
+package board
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+/**
+ * Random keys of the Zobrist hash of a state: the hash is the xor of the keys of its removed tiles, pawns,
+ * player to move and eliminated players. Player 0 to move has no key.
+ */
+type zobristKeys struct {
+	removed      [MAX_GRID_SIZE]uint64
+	pawns        [MAX_PLAYERS][MAX_GRID_SIZE]uint64
+	playerToMove [MAX_PLAYERS]uint64
+	eliminated   [MAX_PLAYERS]uint64
+}
+
+// generated with a fixed seed and not with rng, so that the hashes don't depend on the seed of the search
+var zobrist = newZobristKeys(rand.New(rand.NewSource(42)))
+
+func newZobristKeys(random *rand.Rand) (keys zobristKeys) {
+	for i := 0; i < MAX_GRID_SIZE; i++ {
+		keys.removed[i] = random.Uint64()
+		keys.pawns[0][i] = random.Uint64()
+		keys.pawns[1][i] = random.Uint64()
+	}
+	keys.playerToMove[1] = random.Uint64()
+
+	// drawn after the keys of two players, so that their hashes don't depend on MAX_PLAYERS
+	for playerId := 2; playerId < MAX_PLAYERS; playerId++ {
+		for i := 0; i < MAX_GRID_SIZE; i++ {
+			keys.pawns[playerId][i] = random.Uint64()
+		}
+		keys.playerToMove[playerId] = random.Uint64()
+	}
+	for playerId := 0; playerId < MAX_PLAYERS; playerId++ {
+		keys.eliminated[playerId] = random.Uint64()
+	}
+
+	return keys
+}
+
+func HashState(currentState *State) uint64 {
+	hash := zobrist.pawns[0][TileIndex(currentState.PlayersPosition[0])]
+	hash ^= zobrist.pawns[1][TileIndex(currentState.PlayersPosition[1])]
+
+	for playerId := uint8(2); playerId < PlayersCount; playerId++ {
+		hash ^= zobrist.pawns[playerId][TileIndex(currentState.PlayersPosition[playerId])]
+	}
+
+	hash ^= zobrist.playerToMove[currentState.PlayerToMove]
+
+	for eliminated := currentState.EliminatedPlayers; eliminated != 0; eliminated &= eliminated - 1 {
+		hash ^= zobrist.eliminated[bits.TrailingZeros8(eliminated)]
+	}
+
+	for i, part := range currentState.BoardRemoved.parts {
+		for removed := part; removed != 0; removed &= removed - 1 {
+			hash ^= zobrist.removed[64*i+bits.TrailingZeros64(removed)]
+		}
+	}
+
+	return hash
+}
@@ -0,0 +1,120 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/**
+ * Position notation: the rows from y = 0 separated by /, with . for a free tile, # for a removed tile,
+ * A and B for the pawns of player 0 and 1, C and D for the ones of players 2 and 3, in lowercase once the player is eliminated,
+ * followed by the player to move. The dimensions and the number of players are the ones of the current Config.
+ * Example, the initial position: ........./........./........./........./A.......B/........./........./........./......... 0
+ */
+func FormatPosition(currentState *State) string {
+	var result strings.Builder
+
+	for y := uint8(0); y < Height; y++ {
+		if y > 0 {
+			result.WriteString("/")
+		}
+
+		for x := uint8(0); x < Width; x++ {
+			c := Coord{x, y}
+			playerId := getPlayerAt(currentState, c)
+			switch {
+			case playerId >= 0 && IsEliminated(currentState, uint8(playerId)):
+				result.WriteByte('a' + byte(playerId))
+			case playerId >= 0:
+				result.WriteByte('A' + byte(playerId))
+			case IsTileRemoved(currentState, &c):
+				result.WriteString("#")
+			default:
+				result.WriteString(".")
+			}
+		}
+	}
+
+	fmt.Fprintf(&result, " %d", currentState.PlayerToMove)
+
+	return result.String()
+}
+
+func getPlayerAt(currentState *State, c Coord) int {
+	for playerId := 0; playerId < int(PlayersCount); playerId++ {
+		if currentState.PlayersPosition[playerId] == c {
+			return playerId
+		}
+	}
+	return -1
+}
+
+/**
+ * Parses a position written by FormatPosition, the turn is deduced from the number of removed tiles that are not blocked by the rules.
+ */
+func ParsePosition(s string) (currentState State, err error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return State{}, fmt.Errorf("expected the board and the player to move in %q", s)
+	}
+
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != int(Height) {
+		return State{}, fmt.Errorf("expected %d rows in %q", Height, fields[0])
+	}
+
+	foundPlayers := [MAX_PLAYERS]bool{}
+	removedCount := 0
+
+	for y, row := range rows {
+		if len(row) != int(Width) {
+			return State{}, fmt.Errorf("expected %d tiles in row %d %q", Width, y, row)
+		}
+
+		for x, tile := range row {
+			c := Coord{uint8(x), uint8(y)}
+
+			switch tile {
+			case '.':
+			case '#':
+				currentState.BoardRemoved.Set(TileIndex(c), true)
+				if !Contains(GameRules.BlockedTiles, c) {
+					removedCount++
+				}
+			case 'A', 'B', 'C', 'D', 'a', 'b', 'c', 'd':
+				playerId := uint8(unicode.ToUpper(tile) - 'A')
+				if playerId >= PlayersCount {
+					return State{}, fmt.Errorf("pawn %c of a player not in the game of %d players", tile, PlayersCount)
+				}
+				if foundPlayers[playerId] {
+					return State{}, fmt.Errorf("pawn %c found twice", tile)
+				}
+				foundPlayers[playerId] = true
+				currentState.PlayersPosition[playerId] = c
+				if unicode.IsLower(tile) {
+					currentState.EliminatedPlayers |= 1 << playerId
+				}
+			default:
+				return State{}, fmt.Errorf("invalid tile %q at %v", tile, c)
+			}
+		}
+	}
+
+	for playerId := uint8(0); playerId < PlayersCount; playerId++ {
+		if !foundPlayers[playerId] {
+			return State{}, fmt.Errorf("the pawns of the %d players must be on the board in %q", PlayersCount, fields[0])
+		}
+	}
+
+	currentState.Turn = uint8(removedCount / int(GameRules.Removals))
+
+	playerToMove, err := strconv.ParseUint(fields[1], 10, 8)
+	if err != nil || playerToMove >= uint64(PlayersCount) || IsEliminated(&currentState, uint8(playerToMove)) {
+		return State{}, fmt.Errorf("invalid player to move %q", fields[1])
+	}
+	currentState.PlayerToMove = uint8(playerToMove)
+
+	return currentState, nil
+}
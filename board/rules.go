@@ -0,0 +1,171 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type MoveKind uint8
+
+const (
+	// one step in the 8 directions, the rules of CodinGame
+	KING_MOVES MoveKind = iota
+	// one step in the 4 orthogonal directions
+	ORTHOGONAL_MOVES
+	// the jumps of a chess knight, over removed tiles and pawns
+	KNIGHT_MOVES
+)
+
+var moveKindNames = [...]string{"king", "orthogonal", "knight"}
+
+var moveOffsets = [...][][2]int{
+	KING_MOVES:       {{-1, -1}, {-1, 0}, {-1, 1}, {0, -1}, {0, 1}, {1, -1}, {1, 0}, {1, 1}},
+	ORTHOGONAL_MOVES: {{-1, 0}, {0, -1}, {0, 1}, {1, 0}},
+	KNIGHT_MOVES:     {{-2, -1}, {-2, 1}, {-1, -2}, {-1, 2}, {1, -2}, {1, 2}, {2, -1}, {2, 1}},
+}
+
+// a pawn has at most 8 moves whatever the rules
+const MAX_MOVES = 8
+
+const MAX_REMOVALS = 2
+
+/**
+ * A variant of Isola. A turn is a move of the pawn and the removal of Removals free tiles, in this order unless RemoveFirst.
+ * A player who can't complete a whole turn loses.
+ */
+type Rules struct {
+	Moves MoveKind
+	// remove the tiles before moving the pawn, the pawn can't move to a tile removed in the same turn
+	RemoveFirst bool
+	// the number of tiles removed at each turn, between 1 and MAX_REMOVALS
+	Removals uint8
+	// the tiles removed before the first turn
+	BlockedTiles []Coord
+}
+
+// the rules of CodinGame
+var DEFAULT_RULES = Rules{Moves: KING_MOVES, Removals: 1}
+
+// the rules being played, set by SetRules
+var GameRules = DEFAULT_RULES
+
+/**
+ * Plays the following games with rules, on the current board. The states of the previous rules must not be used anymore.
+ */
+func SetRules(rules Rules) error {
+	if err := rules.Validate(GetConfig()); err != nil {
+		return err
+	}
+
+	GameRules = rules
+	InitAdjacentTilesCache()
+
+	return nil
+}
+
+func (rules Rules) Validate(config Config) error {
+	if int(rules.Moves) >= len(moveKindNames) {
+		return fmt.Errorf("invalid move kind %d", rules.Moves)
+	}
+
+	if rules.Removals < 1 || rules.Removals > MAX_REMOVALS {
+		return fmt.Errorf("invalid number of removals %d: it must be between 1 and %d", rules.Removals, MAX_REMOVALS)
+	}
+
+	for _, tile := range rules.BlockedTiles {
+		if tile.X >= config.Width || tile.Y >= config.Height {
+			return fmt.Errorf("the blocked tile %v is outside of the %dx%d board", tile, config.Width, config.Height)
+		}
+		if Contains(config.StartPositions[:config.PlayersCount], tile) {
+			return fmt.Errorf("the blocked tile %v is a start square", tile)
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Rules notation: the kind of moves followed by the options that differ from the rules of CodinGame,
+ * for example "knight remove-first removals=2 blocked=4,4 blocked=3,5".
+ */
+func FormatRules(rules Rules) string {
+	fields := []string{moveKindNames[rules.Moves]}
+
+	if rules.RemoveFirst {
+		fields = append(fields, "remove-first")
+	}
+	if rules.Removals != 1 {
+		fields = append(fields, fmt.Sprintf("removals=%d", rules.Removals))
+	}
+	for _, tile := range rules.BlockedTiles {
+		fields = append(fields, fmt.Sprintf("blocked=%d,%d", tile.X, tile.Y))
+	}
+
+	return strings.Join(fields, " ")
+}
+
+/**
+ * Parses rules written by FormatRules, in any order. They are validated by SetRules, against the board.
+ */
+func ParseRules(s string) (Rules, error) {
+	rules := DEFAULT_RULES
+
+	for _, field := range strings.Fields(s) {
+		name, value, hasValue := strings.Cut(field, "=")
+
+		switch {
+		case !hasValue && name == "remove-first":
+			rules.RemoveFirst = true
+		case !hasValue && getMoveKind(name) >= 0:
+			rules.Moves = MoveKind(getMoveKind(name))
+		case hasValue && name == "removals":
+			removals, err := strconv.ParseUint(value, 10, 8)
+			if err != nil {
+				return Rules{}, fmt.Errorf("invalid number of removals %q", value)
+			}
+			rules.Removals = uint8(removals)
+		case hasValue && name == "blocked":
+			x, y, err := parsePair(value, ",")
+			if err != nil {
+				return Rules{}, fmt.Errorf("invalid blocked tile %q: %w", value, err)
+			}
+			rules.BlockedTiles = append(rules.BlockedTiles, Coord{x, y})
+		default:
+			return Rules{}, fmt.Errorf("unknown rule %q in %q", field, s)
+		}
+	}
+
+	return rules, nil
+}
+
+func getMoveKind(name string) int {
+	for i, moveKindName := range moveKindNames {
+		if name == moveKindName {
+			return i
+		}
+	}
+	return -1
+}
+
+/**
+ * Tells if a pawn can go from one tile to the other in one move of the rules, whatever the state of the tiles.
+ */
+func IsMove(from Coord, to Coord) bool {
+	dx := int(to.X) - int(from.X)
+	dy := int(to.Y) - int(from.Y)
+
+	for _, offset := range moveOffsets[GameRules.Moves] {
+		if offset[0] == dx && offset[1] == dy {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * The tiles removed by the action, RemoveTile then SecondRemoveTile with two removals per turn.
+ */
+func GetRemovedTiles(a *Action) []Coord {
+	return []Coord{a.RemoveTile, a.SecondRemoveTile}[:GameRules.Removals]
+}
@@ -0,0 +1,183 @@
+package board
+
+import "math/bits"
+
+/**
+ * A transformation of the board that gives the same game, the moves of all the rules are symmetric under each of them.
+ * Each symmetry is its own inverse: the action of a transformed state is transformed back by the same symmetry.
+ */
+type Symmetry uint8
+
+const (
+	SYMMETRY_IDENTITY Symmetry = iota
+	// vertical mirror, y becomes Height - 1 - y
+	SYMMETRY_MIRROR
+	// rotation of 180°, x becomes Width - 1 - x and y becomes Height - 1 - y, players 0 and 1 are swapped
+	SYMMETRY_ROTATION
+	// horizontal mirror, x becomes Width - 1 - x, players 0 and 1 are swapped
+	SYMMETRY_MIRROR_ROTATION
+	SYMMETRIES_COUNT
+)
+
+var symmetryNames = [SYMMETRIES_COUNT]string{"identity", "mirror", "rotation", "mirror rotation"}
+
+func (symmetry Symmetry) String() string {
+	return symmetryNames[symmetry]
+}
+
+/**
+ * The symmetries of the current board and rules, identity first, and the tile index of each tile transformed by each symmetry.
+ * They are updated by InitAdjacentTilesCache with the board and the rules.
+ */
+var boardSymmetries = []Symmetry{SYMMETRY_IDENTITY}
+var symmetricTileIndexes [SYMMETRIES_COUNT][MAX_GRID_SIZE]uint8
+
+func initSymmetries() {
+	boardSymmetries = boardSymmetries[:0]
+
+	for symmetry := SYMMETRY_IDENTITY; symmetry < SYMMETRIES_COUNT; symmetry++ {
+		for _, tile := range GetTiles() {
+			symmetricTileIndexes[symmetry][TileIndex(tile)] = TileIndex(TransformCoord(tile, symmetry))
+		}
+
+		if isSymmetry(symmetry) {
+			boardSymmetries = append(boardSymmetries, symmetry)
+		}
+	}
+}
+
+/**
+ * Tells if the game is the same after the symmetry: every start square is transformed into the one of the transformed player,
+ * and every blocked tile into a blocked tile. The players are only swapped in the games of two players.
+ */
+func isSymmetry(symmetry Symmetry) bool {
+	if symmetry.swapsPlayers() && PlayersCount != 2 {
+		return false
+	}
+
+	for playerId := uint8(0); playerId < PlayersCount; playerId++ {
+		if TransformCoord(StartPositions[playerId], symmetry) != StartPositions[TransformPlayer(playerId, symmetry)] {
+			return false
+		}
+	}
+
+	for _, tile := range GameRules.BlockedTiles {
+		if !Contains(GameRules.BlockedTiles, TransformCoord(tile, symmetry)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func GetSymmetries() []Symmetry {
+	return boardSymmetries
+}
+
+func (symmetry Symmetry) swapsPlayers() bool {
+	return symmetry == SYMMETRY_ROTATION || symmetry == SYMMETRY_MIRROR_ROTATION
+}
+
+func TransformCoord(c Coord, symmetry Symmetry) Coord {
+	switch symmetry {
+	case SYMMETRY_MIRROR:
+		return Coord{c.X, Height - 1 - c.Y}
+	case SYMMETRY_ROTATION:
+		return Coord{Width - 1 - c.X, Height - 1 - c.Y}
+	case SYMMETRY_MIRROR_ROTATION:
+		return Coord{Width - 1 - c.X, c.Y}
+	}
+	return c
+}
+
+func TransformPlayer(playerId uint8, symmetry Symmetry) uint8 {
+	if symmetry.swapsPlayers() && playerId < 2 {
+		return 1 - playerId
+	}
+	return playerId
+}
+
+func TransformState(currentState *State, symmetry Symmetry) State {
+	transformedState := State{Turn: currentState.Turn, PlayerToMove: TransformPlayer(currentState.PlayerToMove, symmetry)}
+
+	for playerId := uint8(0); playerId < PlayersCount; playerId++ {
+		transformedPlayerId := TransformPlayer(playerId, symmetry)
+		transformedState.PlayersPosition[transformedPlayerId] = TransformCoord(currentState.PlayersPosition[playerId], symmetry)
+		if currentState.EliminatedPlayers&(1<<playerId) != 0 {
+			transformedState.EliminatedPlayers |= 1 << transformedPlayerId
+		}
+	}
+
+	for _, tile := range GetTiles() {
+		if IsTileRemoved(currentState, &tile) {
+			transformedState.BoardRemoved.Set(symmetricTileIndexes[symmetry][TileIndex(tile)], true)
+		}
+	}
+
+	return transformedState
+}
+
+/**
+ * The action of the transformed state, the removed tiles stay in the order of the move generator.
+ */
+func TransformAction(a *Action, symmetry Symmetry) Action {
+	transformedAction := Action{MovePosition: TransformCoord(a.MovePosition, symmetry), RemoveTile: TransformCoord(a.RemoveTile, symmetry)}
+
+	if GameRules.Removals == 2 {
+		transformedAction.SecondRemoveTile = TransformCoord(a.SecondRemoveTile, symmetry)
+		if TileIndex(transformedAction.SecondRemoveTile) < TileIndex(transformedAction.RemoveTile) {
+			transformedAction.RemoveTile, transformedAction.SecondRemoveTile = transformedAction.SecondRemoveTile, transformedAction.RemoveTile
+		}
+	}
+
+	return transformedAction
+}
+
+/**
+ * The lowest hash of the state transformed by the symmetries of the board and the symmetry that gives it:
+ * the symmetric states share the hash of their representative, and an action of the state is
+ * TransformAction(a, symmetry) in the representative.
+ */
+func GetCanonicalHash(currentState *State) (hash uint64, symmetry Symmetry) {
+	hash = HashState(currentState)
+
+	for _, s := range boardSymmetries[1:] {
+		if symmetricHash := hashTransformedState(currentState, s); symmetricHash < hash {
+			hash, symmetry = symmetricHash, s
+		}
+	}
+
+	return hash, symmetry
+}
+
+/**
+ * HashState(TransformState(currentState, symmetry)) without building the transformed state.
+ */
+func hashTransformedState(currentState *State, symmetry Symmetry) uint64 {
+	indexes := &symmetricTileIndexes[symmetry]
+	hash := zobrist.playerToMove[TransformPlayer(currentState.PlayerToMove, symmetry)]
+
+	for playerId := uint8(0); playerId < PlayersCount; playerId++ {
+		transformedPlayerId := TransformPlayer(playerId, symmetry)
+		hash ^= zobrist.pawns[transformedPlayerId][indexes[TileIndex(currentState.PlayersPosition[playerId])]]
+		if currentState.EliminatedPlayers&(1<<playerId) != 0 {
+			hash ^= zobrist.eliminated[transformedPlayerId]
+		}
+	}
+
+	for i, part := range currentState.BoardRemoved.parts {
+		for removed := part; removed != 0; removed &= removed - 1 {
+			hash ^= zobrist.removed[indexes[64*i+bits.TrailingZeros64(removed)]]
+		}
+	}
+
+	return hash
+}
+
+/**
+ * The representative of the state among its symmetric states, and the symmetry that transforms the state into it.
+ */
+func CanonicalizeState(currentState *State) (State, Symmetry) {
+	_, symmetry := GetCanonicalHash(currentState)
+	return TransformState(currentState, symmetry), symmetry
+}
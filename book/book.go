@@ -0,0 +1,79 @@
+package book
+
+import (
+	"sort"
+
+	"isola/board"
+)
+
+/**
+ * Opening book: the best action of the positions of the first plies, searched offline for much longer than a turn.
+ * An entry is the key of a position in its high 40 bits and its action in the low 24 bits, sorted by key.
+ * The symmetric positions share the entry of their representative, see board.GetCanonicalHash, whose action is stored.
+ */
+type Book struct {
+	// the board and the rules of the positions, in the notations of board.FormatConfig and board.FormatRules
+	Config  string
+	Rules   string
+	Entries []uint64
+}
+
+// the book embedded in the engine, generated in table.go by the book command of cmd/isola
+var DefaultBook = Book{Config: BOOK_CONFIG, Rules: BOOK_RULES, Entries: bookEntries}
+
+const BOOK_ACTION_BITS = 24
+const BOOK_ACTION_MASK = 1<<BOOK_ACTION_BITS - 1
+
+/**
+ * The 4 bits of each coordinate of the action, the second removed tile is 0 0 with one removal per turn.
+ */
+func EncodeBookAction(a *board.Action) uint64 {
+	encoded := uint64(0)
+	for _, c := range []board.Coord{a.MovePosition, a.RemoveTile, a.SecondRemoveTile} {
+		encoded = encoded<<8 | uint64(c.X)<<4 | uint64(c.Y)
+	}
+	return encoded
+}
+
+func decodeBookAction(encoded uint64) board.Action {
+	var coords [3]board.Coord
+	for i := len(coords) - 1; i >= 0; i-- {
+		coords[i] = board.Coord{X: uint8(encoded>>4) & 0xF, Y: uint8(encoded) & 0xF}
+		encoded >>= 8
+	}
+	return board.Action{MovePosition: coords[0], RemoveTile: coords[1], SecondRemoveTile: coords[2]}
+}
+
+/**
+ * The key of the position in the book, the one of its representative, and the symmetry that transforms it into the representative.
+ */
+func GetBookKey(currentState *board.State) (key uint64, symmetry board.Symmetry) {
+	hash, symmetry := board.GetCanonicalHash(currentState)
+	return hash &^ BOOK_ACTION_MASK, symmetry
+}
+
+/**
+ * The action of the book for the player to move, if the position is in the book of the current board and rules.
+ */
+func (b *Book) Lookup(currentState *board.State) (board.Action, bool) {
+	if b.Config != board.FormatConfig(board.GetConfig()) || b.Rules != board.FormatRules(board.GameRules) {
+		return board.Action{}, false
+	}
+
+	key, symmetry := GetBookKey(currentState)
+
+	i := sort.Search(len(b.Entries), func(i int) bool { return b.Entries[i]&^BOOK_ACTION_MASK >= key })
+	if i == len(b.Entries) || b.Entries[i]&^BOOK_ACTION_MASK != key {
+		return board.Action{}, false
+	}
+
+	representativeAction := decodeBookAction(b.Entries[i] & BOOK_ACTION_MASK)
+	a := board.TransformAction(&representativeAction, symmetry)
+
+	// the key is only a part of the hash, a collision must not play an illegal action
+	if board.ValidateAction(currentState, &a, currentState.PlayerToMove) != nil {
+		return board.Action{}, false
+	}
+
+	return a, true
+}
@@ -0,0 +1,60 @@
+package book
+
+import (
+	"testing"
+
+	"isola/board"
+	"isola/movegen"
+)
+
+func setConfig(t *testing.T, notation string) {
+	config, err := board.ParseConfig(notation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := board.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { board.SetConfig(board.DEFAULT_CONFIG) })
+}
+
+func TestBookActionEncoding(t *testing.T) {
+	for _, a := range []board.Action{
+		{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 8, Y: 3}},
+		{MovePosition: board.Coord{X: 15, Y: 0}, RemoveTile: board.Coord{X: 0, Y: 15}, SecondRemoveTile: board.Coord{X: 15, Y: 15}},
+	} {
+		encoded := EncodeBookAction(&a)
+		if encoded&^BOOK_ACTION_MASK != 0 || decodeBookAction(encoded) != a {
+			t.Errorf("%s encoded as %x decoded as %v", board.FormatAction(&a), encoded, decodeBookAction(encoded))
+		}
+	}
+}
+
+func TestDefaultBook(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	initial := board.InitialState()
+
+	a, ok := DefaultBook.Lookup(&initial)
+	if !ok {
+		t.Fatal("no action for the initial position")
+	}
+	if err := board.ValidateAction(&initial, &a, 0); err != nil {
+		t.Fatalf("%s: %v", board.FormatAction(&a), err)
+	}
+
+	// player 1 finds its first position whatever player 0 plays
+	for _, firstAction := range movegen.GetLegalActions(&initial, 0) {
+		firstState := board.ApplyAction(&initial, &firstAction)
+		if a, ok := DefaultBook.Lookup(&firstState); !ok || board.ValidateAction(&firstState, &a, 1) != nil {
+			t.Fatalf("%s: book action %s %v", board.FormatAction(&firstAction), board.FormatAction(&a), ok)
+		}
+	}
+
+	// another board doesn't use the book
+	setConfig(t, "9x7")
+	initial = board.InitialState()
+	if a, ok := DefaultBook.Lookup(&initial); ok {
+		t.Errorf("%s played on another board", board.FormatAction(&a))
+	}
+}
@@ -0,0 +1,61 @@
+// Code generated by the book command of cmd/isola. DO NOT EDIT.
+
+package book
+
+// 2 plies, 202 positions searched to a depth of 6 to 7 plies, 2424000000 nodes, with -time 0s -depth 0 -nodes 12000000 in 2h21m1s
+const BOOK_CONFIG = "9x9 0,4 8,4"
+const BOOK_RULES = "king"
+
+var bookEntries = []uint64{
+	0x02042a82b5742300, 0x023a5e71eb742300, 0x031cf8fc31742500, 0x0354e0f4ac752400,
+	0x039dec27f0741600, 0x041ed245af731300, 0x04c44957c5136400, 0x04c9278f56751400,
+	0x052ad57e22136400, 0x05d7835307732400, 0x05dd8975a9146500, 0x064a0412e6752500,
+	0x0660903229146400, 0x0686d2ea22156400, 0x0729926500136300, 0x07a707e752742500,
+	0x07fda6fdb3742500, 0x082f9b8e90147200, 0x0847bf0199742400, 0x08ba1a73c1146500,
+	0x095180b97d146400, 0x098b1bab17731400, 0x09a461c717147400, 0x09db11b1b7146500,
+	0x0a373bb5cc742400, 0x0a6d9aaf2d742400, 0x0ac840a945146500, 0x0acc72df5f741600,
+	0x0b89112cb2148500, 0x0bda384078742500, 0x0c6c425847740600, 0x0c7fcf6ae9148600,
+	0x0ca6f7a6eb147400, 0x0d134a3b7d156500, 0x0d54f0bb70146300, 0x0d8e6ba91a741400,
+	0x0d9020c0c5137600, 0x0dafbc72d8741500, 0x0e26780174146300, 0x0e2b9d71f1157400,
+	0x0e75bb05bd136300, 0x0e7ba0bee1147400, 0x0e8cc4aeaf732400, 0x0e9eef67c4136400,
+	0x0ec4dca632752400, 0x0efce3131e741400, 0x0f40eab491137400, 0x0f772b24ea156400,
+	0x0fadb03680741400, 0x10f05b3cd0742300, 0x112889ab62148200, 0x1179fb9874751300,
+	0x11a3608a1e156400, 0x12707220cb136400, 0x1280df8885742300, 0x12aae932a1741200,
+	0x12da7e9264742300, 0x13e782d76d146400, 0x14057c8402146300, 0x14dfe79668741400,
+	0x154ae7dbb9741300, 0x15907cc9d3136300, 0x15e79ab879147400, 0x160401cc44741400,
+	0x163b2093e6742300, 0x167c4203c4137400, 0x16bcc127f2147600, 0x171d861e3c742500,
+	0x1723f2ed62742500, 0x18791753cd147200, 0x19a908fb1e148600, 0x1a8dba4ca2742300,
+	0x1ab3cebffc752500, 0x1aba59e3ec138600, 0x1b11044b85740600, 0x1bdb52219a147500,
+	0x1da26803a5146500, 0x1df58f3803741500, 0x1dffa7359f147400, 0x1e13922a79740600,
+	0x1e19fb25b9741400, 0x1e72026926147400, 0x1ef168929e136500, 0x1efde02faf742300,
+	0x1f15dc0821157300, 0x1f1ad9fe4a157300, 0x1f1e8738b2146500, 0x1fc7d359e1146400,
+	0x20f31dcedb752300, 0x211ac0429f156400, 0x2120193d11742400, 0x2138bd0acb742400,
+	0x22c8e4078f157600, 0x22d787ff5f146400, 0x231b7c23ab156400, 0x23ef31e87e742500,
+	0x2478851544742500, 0x24ade25505146500, 0x2559180312146500, 0x268467fd40741300,
+	0x2719079ffe146300, 0x2734ec23e1137500, 0x2761531729146300, 0x2793df438b742400,
+	0x27bbc80543741400, 0x28db559531741500, 0x29e8b947da732400, 0x2a03e31115732400,
+	0x2a72e2284e136400, 0x2a90995227146300, 0x2aa7633892157400, 0x2cb0256f8f742500,
+	0x2d262980e8146400, 0x2d4ed76a63742200, 0x2df11c1a18146500, 0x2e3b0447bc752400,
+	0x314af3dfd0146500, 0x317c16fef9740600, 0x31a9ac55d4156500, 0x320f982350146300,
+	0x327b1b2e37147200, 0x34213ba51a146300, 0x35aac1ec9d146300, 0x362b5b1c2e147500,
+	0x3638505149147400, 0x363d250e19146300, 0x36c8e987a9742300, 0x36e7be1c73751200,
+	0x382aa6f852742400, 0x3844f9f392852400, 0x38690f05b4742500, 0x39247555a8137400,
+	0x3952d7baf5741200, 0x398f593782742300, 0x39fdb2a393751200, 0x3a125a0428740200,
+	0x3ac8c11642146300, 0x3cd48cdec6741600, 0x3d5c477075740600, 0x4041181b17742300,
+	0x404e1ded7c752400, 0x40c5081092146500, 0x41a621caf2147300, 0x425382041a732500,
+	0x435af8fe78741200, 0x43633c6616156300, 0x43c8230bcc146400, 0x43d8784d9d742500,
+	0x44f7b53ddb146500, 0x4645af5012741200, 0x46daee2c61732400, 0x4802512580146500,
+	0x48accf6c85742400, 0x492783e699742300, 0x4b3b4133be147600, 0x4bd6204b61731200,
+	0x4bedb9823b157600, 0x4d6e65896b146400, 0x4ddb1e6dd8147300, 0x4fd4fa1824741400,
+	0x520a3cb444146300, 0x53fd3643b6742300, 0x54a53e5e5f742500, 0x55fcce6f62741400,
+	0x573c9fba5e146300, 0x58f6fc265e742500, 0x5c715ab015156300, 0x5cfb826c60732400,
+	0x5d1eff551f751400, 0x5d619f94b6137400, 0x5f6e7be14a740600, 0x641df0d8de742500,
+	0x65953b766d742500, 0x65e1fa1fcd146300, 0x6b323f91d4157300, 0x6c3bf8a15c136300,
+	0x6cff28dae9148600, 0x6edcf34573158600, 0x7496d9cb8a741200, 0x75265955ac136400,
+	0x78d84b88ed751300, 0x7b9b108abf137600, 0x7ec8508dbc742300, 0x7f03411ad5751400,
+	0x80247826c6741200, 0x80f1b5e6dd751400, 0x82d4c69a18146300, 0x830b271687741400,
+	0x84b5019af5752300, 0x84c5262744731400, 0x84fa0778e6742300, 0x884abab2b0741600,
+	0x8a57b5726f742500, 0x9244d06a4f137400, 0x98e30bbb4a742300, 0x9f706d1db8752400,
+	0xad00a6d80a156200, 0xadf22a8ca8742400, 0xb2b9df63d3146400, 0xb2d7806813146300,
+	0xb3b4502a78752400, 0xc9131a7793742400,
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+	"isola/search"
+)
+
+func init() {
+	commands["analyze"] = analyzeCommand
+}
+
+/**
+ * Prints the score, depth reached and principal variation of every root action of a position.
+ */
+func analyzeCommand(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	position := flags.String("position", "", "position to analyze, see board.FormatPosition (default the initial position)")
+	boardFlags := addBoardFlags(flags)
+	moveTime := flags.Duration("time", 5*time.Second, "time limit, 0 for none")
+	maxDepth := flags.Int("depth", 0, "depth limit, 0 for none")
+	maxNodes := flags.Int("nodes", 0, "node limit, 0 for none")
+	multiPV := flags.Int("multipv", 0, "number of best root actions to report, 0 for all")
+	seed := flags.Int64("seed", search.DEFAULT_SEED, "seed of the random move ordering")
+	hashSize := flags.Int("hash", search.DEFAULT_TABLE_SIZE_MB, "memory used by the transposition table in MB")
+	flags.IntVar(&search.QuiescencePlies, "quiescence", search.QuiescencePlies, "plies searched after the horizon in the tactical positions, 0 to evaluate the horizon directly")
+	flags.BoolVar(&search.LateMoveReductions, "lmr", search.LateMoveReductions, "search the actions ordered late a ply shallower unless they improve the best value")
+	flags.BoolVar(&search.SingleMoveExtensions, "extensions", search.SingleMoveExtensions, "search a ply deeper the positions where the player to move has a single move")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	currentState := board.InitialState()
+	if *position != "" {
+		var err error
+		if currentState, err = board.ParsePosition(*position); err != nil {
+			return err
+		}
+	}
+
+	search.SetSeed(*seed)
+	search.SetTableSize(*hashSize)
+
+	analyzePosition(os.Stdout, &currentState, *multiPV, search.SearchLimits{MoveTime: *moveTime, Depth: *maxDepth, Nodes: *maxNodes})
+
+	return nil
+}
+
+/**
+ * Prints the board and the results of the search of the position, or that the player to move has no legal action.
+ */
+func analyzePosition(w io.Writer, currentState *board.State, multiPV int, limits search.SearchLimits) {
+	fmt.Fprint(w, eval.RenderBoard(currentState, eval.RenderOptions{Territory: true}))
+	fmt.Fprintf(w, "player %d to move\n", currentState.PlayerToMove)
+
+	if !movegen.CanPlay(currentState, currentState.PlayerToMove) {
+		fmt.Fprintf(w, "no legal action for player %d\n", currentState.PlayerToMove)
+		return
+	}
+
+	results := search.FindBestMoves(currentState, multiPV, search.NewTimeManager(time.Now(), limits))
+
+	writeSearchResults(w, results)
+}
+
+func writeSearchResults(w io.Writer, results []search.SearchResult) {
+	for i, result := range results {
+		pv := make([]string, len(result.PV))
+		for j := range result.PV {
+			pv[j] = board.FormatAction(&result.PV[j])
+		}
+
+		fmt.Fprintf(w, "%3d. %s  score %8d  depth %2d  pv %s\n", i+1, board.FormatAction(&result.Action), result.Score, result.Depth, strings.Join(pv, " | "))
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"isola/board"
+	"isola/book"
+	"isola/movegen"
+	"isola/search"
+)
+
+func init() {
+	commands["book"] = bookCommand
+}
+
+/**
+ * Searches the positions of the first plies for long and writes the opening book embedded in the engine.
+ */
+func bookCommand(args []string) error {
+	flags := flag.NewFlagSet("book", flag.ContinueOnError)
+	plies := flags.Int("plies", 2, "number of plies of the book, the positions grow with every legal action of the opponent")
+	boardFlags := addBoardFlags(flags)
+	moveTime := flags.Duration("time", 10*time.Second, "time limit of each position, 0 for none")
+	maxDepth := flags.Int("depth", 0, "depth limit of each position, 0 for none")
+	maxNodes := flags.Int("nodes", 0, "node limit of each position, 0 for none")
+	hashSize := flags.Int("hash", search.DEFAULT_TABLE_SIZE_MB, "memory used by the transposition table in MB")
+	outPath := flags.String("out", "book/table.go", "Go file of the book")
+	quiet := flags.Bool("quiet", false, "don't print the action of each position")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	if board.PlayersCount != 2 {
+		return fmt.Errorf("only the games of two players have a book")
+	}
+
+	search.SetTableSize(*hashSize)
+
+	var progress io.Writer = os.Stdout
+	if *quiet {
+		progress = io.Discard
+	}
+
+	startedAt := time.Now()
+
+	limits := search.SearchLimits{MoveTime: *moveTime, Depth: *maxDepth, Nodes: *maxNodes}
+	b, stats := buildBook(*plies, limits, progress)
+
+	duration := time.Since(startedAt).Round(time.Second)
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	comment := fmt.Sprintf("%d plies, %d positions searched to a depth of %d to %d plies, %d nodes, with -time %v -depth %d -nodes %d in %v",
+		*plies, len(b.Entries), stats.minDepth, stats.maxDepth, stats.nodes, *moveTime, *maxDepth, *maxNodes, duration)
+	if err := writeBookGo(f, b, comment); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%s, written to %s\n", comment, *outPath)
+	return f.Close()
+}
+
+/**
+ * What the searches of the positions of a book reached, written in the header of the book.
+ */
+type bookStats struct {
+	nodes    int
+	minDepth int
+	maxDepth int
+}
+
+/**
+ * Builds the book of the current board and rules for the positions of the first plies of a game of two players:
+ * the initial position and every position after the first action for player 1, then after each position of the book
+ * the ones reached by its action and every legal action of the opponent, so that each player finds its positions
+ * whatever the opponent plays. Each position is searched within limits.
+ */
+func buildBook(plies int, limits search.SearchLimits, progress io.Writer) (*book.Book, bookStats) {
+	b := &book.Book{Config: board.FormatConfig(board.GetConfig()), Rules: board.FormatRules(board.GameRules)}
+	keys := map[uint64]bool{}
+	stats := bookStats{}
+
+	initial := board.InitialState()
+	positionsByPly := make([][]board.State, plies+2)
+	positionsByPly[0] = []board.State{initial}
+	positionsByPly[1] = appendNextStates(nil, &initial)
+
+	for ply := 0; ply < plies; ply++ {
+		for i := range positionsByPly[ply] {
+			currentState := &positionsByPly[ply][i]
+
+			key, symmetry := book.GetBookKey(currentState)
+			if keys[key] || !movegen.CanPlay(currentState, currentState.PlayerToMove) {
+				continue
+			}
+			keys[key] = true
+
+			tm := search.NewTimeManager(time.Now(), limits)
+			bestAction, bestScore := search.FindBestMove(currentState, currentState.PlayerToMove, tm)
+			if bestAction == nil {
+				continue
+			}
+
+			stats.nodes += tm.Nodes()
+			if len(b.Entries) == 0 || tm.DepthReached() < stats.minDepth {
+				stats.minDepth = tm.DepthReached()
+			}
+			if tm.DepthReached() > stats.maxDepth {
+				stats.maxDepth = tm.DepthReached()
+			}
+
+			bookAction := board.TransformAction(bestAction, symmetry)
+			b.Entries = append(b.Entries, key|book.EncodeBookAction(&bookAction))
+
+			fmt.Fprintf(progress, "ply %d, position %d/%d: %s, score %d, depth %d\n", ply, i+1, len(positionsByPly[ply]), board.FormatAction(bestAction), bestScore, tm.DepthReached())
+
+			if ply+2 < plies {
+				nextState := board.ApplyAction(currentState, bestAction)
+				positionsByPly[ply+2] = appendNextStates(positionsByPly[ply+2], &nextState)
+			}
+		}
+	}
+
+	sort.Slice(b.Entries, func(i, j int) bool { return b.Entries[i] < b.Entries[j] })
+
+	return b, stats
+}
+
+/**
+ * Appends the states reached by every legal action of the player to move.
+ */
+func appendNextStates(states []board.State, currentState *board.State) []board.State {
+	for _, a := range movegen.GetLegalActions(currentState, currentState.PlayerToMove) {
+		states = append(states, board.ApplyAction(currentState, &a))
+	}
+	return states
+}
+
+/**
+ * Writes the book as the Go source of book/table.go, to be embedded in the engine and the bundle.
+ */
+func writeBookGo(w io.Writer, b *book.Book, comment string) error {
+	var source strings.Builder
+
+	source.WriteString("// Code generated by the book command of cmd/isola. DO NOT EDIT.\n\n")
+	source.WriteString("package book\n\n")
+	fmt.Fprintf(&source, "// %s\n", comment)
+	fmt.Fprintf(&source, "const BOOK_CONFIG = %q\n", b.Config)
+	fmt.Fprintf(&source, "const BOOK_RULES = %q\n\n", b.Rules)
+	source.WriteString("var bookEntries = []uint64{")
+
+	for i, entry := range b.Entries {
+		if i%4 == 0 {
+			source.WriteString("\n\t")
+		} else {
+			source.WriteString(" ")
+		}
+		fmt.Fprintf(&source, "0x%016x,", entry)
+	}
+	source.WriteString("\n}\n")
+
+	_, err := io.WriteString(w, source.String())
+	return err
+}
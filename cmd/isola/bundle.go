@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	commands["bundle"] = bundleCommand
+}
+
+// the file of this package that is bundled, the other ones are the local tools
+const BUNDLE_MAIN_FILE = "main.go"
+
+// CodinGame refuses a longer source, in characters
+const BUNDLE_MAX_SIZE = 100000
+
+/**
+ * Writes the single file submitted to CodinGame: main.go merged with the packages of the module it imports.
+ */
+func bundleCommand(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ContinueOnError)
+	moduleDir := flags.String("module", ".", "root directory of the module")
+	outPath := flags.String("out", "", "output file (default stdout)")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	source, err := bundle(*moduleDir)
+	if err != nil {
+		return err
+	}
+
+	if size := utf8.RuneCount(source); size > BUNDLE_MAX_SIZE {
+		fmt.Fprintf(os.Stderr, "the bundle has %d characters, CodinGame accepts %d\n", size, BUNDLE_MAX_SIZE)
+	}
+
+	if *outPath == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(*outPath, source, 0644)
+}
+
+type bundledFile struct {
+	path   string
+	source []byte
+	file   *ast.File
+}
+
+/**
+ * Merges cmd/isola/main.go and the packages of the module it imports, dependencies first, into one file of package main.
+ * The qualifiers of the packages of the module are removed, so their top level names must be unique across the bundle.
+ */
+func bundle(moduleDir string) ([]byte, error) {
+	modulePath, err := readModulePath(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	mainFile, err := parseBundledFile(fset, filepath.Join(moduleDir, "cmd", "isola", BUNDLE_MAIN_FILE))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*bundledFile, 0)
+	visitedPackages := map[string]bool{}
+
+	// depth first, so that a package comes after the packages it imports
+	var addImports func(f *bundledFile) error
+	addImports = func(f *bundledFile) error {
+		for _, importSpec := range f.file.Imports {
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+			if !strings.HasPrefix(importPath, modulePath+"/") || visitedPackages[importPath] {
+				continue
+			}
+			visitedPackages[importPath] = true
+
+			packageFiles, err := parsePackage(fset, filepath.Join(moduleDir, strings.TrimPrefix(importPath, modulePath+"/")))
+			if err != nil {
+				return err
+			}
+
+			for _, packageFile := range packageFiles {
+				if err := addImports(packageFile); err != nil {
+					return err
+				}
+			}
+
+			files = append(files, packageFiles...)
+		}
+		return nil
+	}
+
+	if err := addImports(mainFile); err != nil {
+		return nil, err
+	}
+	files = append(files, mainFile)
+
+	imports := map[string]string{}
+	declarations := map[string]string{}
+	var body bytes.Buffer
+
+	for _, f := range files {
+		moduleImports := map[string]bool{}
+
+		for _, importSpec := range f.file.Imports {
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+
+			if strings.HasPrefix(importPath, modulePath+"/") {
+				moduleImports[getImportName(importSpec, importPath)] = true
+				continue
+			}
+
+			name := ""
+			if importSpec.Name != nil {
+				name = importSpec.Name.Name
+			}
+
+			key := name + " " + importPath
+			imports[key] = importPath
+		}
+
+		for _, decl := range f.file.Decls {
+			for _, name := range getDeclarationNames(decl) {
+				if previousPath, ok := declarations[name]; ok {
+					return nil, fmt.Errorf("%s is declared in %s and %s", name, previousPath, f.path)
+				}
+				declarations[name] = f.path
+			}
+		}
+
+		fmt.Fprintf(&body, "\n// %s\n", filepath.ToSlash(f.path))
+		body.Write(removeQualifiersAndComments(fset, f, moduleImports))
+	}
+
+	var result bytes.Buffer
+	result.WriteString("// Code generated by \"go run ./cmd/isola bundle\". DO NOT EDIT.\n\npackage main\n\nimport (\n")
+
+	importKeys := make([]string, 0, len(imports))
+	for key := range imports {
+		importKeys = append(importKeys, key)
+	}
+	sort.Slice(importKeys, func(i, j int) bool {
+		return imports[importKeys[i]] < imports[importKeys[j]]
+	})
+	for _, key := range importKeys {
+		fmt.Fprintf(&result, "\t%s\n", strings.TrimSpace(strings.Replace(key, imports[key], strconv.Quote(imports[key]), 1)))
+	}
+
+	result.WriteString(")\n")
+	result.Write(body.Bytes())
+
+	return format.Source(result.Bytes())
+}
+
+func readModulePath(goModPath string) (string, error) {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			return fields[1], nil
+		}
+	}
+
+	return "", fmt.Errorf("no module path in %s", goModPath)
+}
+
+func parseBundledFile(fset *token.FileSet, path string) (*bundledFile, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := parser.ParseFile(fset, path, source, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bundledFile{path, source, file}, nil
+}
+
+/**
+ * The files of a package without its tests, sorted by name.
+ */
+func parsePackage(fset *token.FileSet, dir string) ([]*bundledFile, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*bundledFile, 0, len(paths))
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+
+		f, err := parseBundledFile(fset, path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go file in %s", dir)
+	}
+
+	return files, nil
+}
+
+func getImportName(importSpec *ast.ImportSpec, importPath string) string {
+	if importSpec.Name != nil {
+		return importSpec.Name.Name
+	}
+	return importPath[strings.LastIndex(importPath, "/")+1:]
+}
+
+/**
+ * The top level names declared by a declaration, methods and init functions can't conflict.
+ */
+func getDeclarationNames(decl ast.Decl) []string {
+	names := make([]string, 0)
+
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil && d.Name.Name != "init" {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					if name.Name != "_" {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+/**
+ * The source of the declarations of the file after its imports, with "pkg." removed from the references to the packages of the module,
+ * and without comments so that the bundle fits in the size accepted by CodinGame.
+ */
+func removeQualifiersAndComments(fset *token.FileSet, f *bundledFile, moduleImports map[string]bool) []byte {
+	tokenFile := fset.File(f.file.Pos())
+
+	start := tokenFile.Offset(f.file.Name.End())
+	for _, decl := range f.file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			start = tokenFile.Offset(genDecl.End())
+		}
+	}
+
+	// the parts of the source that are removed: the qualifiers and the comments
+	type removedPart struct{ start, end int }
+	removedParts := make([]removedPart, 0)
+
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		// an identifier not resolved in the file is a package name
+		if id, ok := selector.X.(*ast.Ident); ok && id.Obj == nil && moduleImports[id.Name] {
+			removedParts = append(removedParts, removedPart{tokenFile.Offset(id.Pos()), tokenFile.Offset(selector.Sel.Pos())})
+		}
+		return true
+	})
+
+	for _, group := range f.file.Comments {
+		for _, comment := range group.List {
+			if !strings.HasPrefix(comment.Text, "//go:") {
+				removedParts = append(removedParts, removedPart{tokenFile.Offset(comment.Pos()), tokenFile.Offset(comment.End())})
+			}
+		}
+	}
+
+	sort.Slice(removedParts, func(i, j int) bool { return removedParts[i].start < removedParts[j].start })
+
+	var result bytes.Buffer
+	position := start
+	for _, part := range removedParts {
+		if part.start < start {
+			continue
+		}
+		result.Write(f.source[position:part.start])
+		position = part.end
+	}
+	result.Write(f.source[position:])
+
+	return result.Bytes()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	_ "net/http/pprof"
+	"os"
+	_ "runtime/pprof"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/protocol"
+	"isola/search"
+)
+
+var LOCAL = os.Getenv("LOCAL") == "true"
+
+/**
+ * Local tools registered by the other files of the package, run with "go run ./cmd/isola <command> [flags]".
+ * main.go and the packages it imports are bundled into the file submitted to CodinGame, so they must not depend on them.
+ */
+var commands = map[string]func(args []string) error{}
+
+func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	seed := flag.Int64("seed", search.DEFAULT_SEED, "seed of the random move ordering")
+	flag.IntVar(&search.FixedNodes, "nodes", 0, "search each action for this number of nodes instead of using the time, 0 to use the time")
+	flag.BoolVar(&protocol.UseBook, "book", true, "play the actions of the opening book while the position is in it")
+	flag.IntVar(&search.QuiescencePlies, "quiescence", search.QuiescencePlies, "plies searched after the horizon in the tactical positions, 0 to evaluate the horizon directly")
+	flag.BoolVar(&search.LateMoveReductions, "lmr", search.LateMoveReductions, "search the actions ordered late a ply shallower unless they improve the best value")
+	flag.BoolVar(&search.SingleMoveExtensions, "extensions", search.SingleMoveExtensions, "search a ply deeper the positions where the player to move has a single move")
+	flag.IntVar(&search.AspirationWindow, "aspiration", search.AspirationWindow, "half width of the window around the score of the previous depth, 0 to search each depth with a full window")
+	hashSize := flag.Int("hash", search.DEFAULT_TABLE_SIZE_MB, "memory used by the transposition table in MB")
+	boardFlags := addBoardFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := boardFlags.set(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	search.SetSeed(*seed)
+	search.SetTableSize(*hashSize)
+
+	if LOCAL {
+		println("local mode")
+		mainLocal()
+	} else {
+		search.Debug("cg mode")
+		mainCG()
+	}
+}
+
+/**
+ * The -board and -rules flags of the engine and of the tools, applied by set once the flags are parsed.
+ */
+type boardFlags struct {
+	config *string
+	rules  *string
+}
+
+func addBoardFlags(flags *flag.FlagSet) boardFlags {
+	return boardFlags{
+		flags.String("board", board.FormatConfig(board.DEFAULT_CONFIG), "dimensions of the board and start squares of player 0 and 1, \"7x7\" starts in the middle of the first and last columns"),
+		flags.String("rules", board.FormatRules(board.DEFAULT_RULES), "kind of moves (king, orthogonal or knight) and options: remove-first, removals=2, blocked=x,y"),
+	}
+}
+
+func (f boardFlags) set() error {
+	config, err := board.ParseConfig(*f.config)
+	if err != nil {
+		return err
+	}
+
+	rules, err := board.ParseRules(*f.rules)
+	if err != nil {
+		return err
+	}
+
+	// the blocked tiles of the rules are checked against the new board
+	if err := board.SetConfig(config); err != nil {
+		return err
+	}
+	return board.SetRules(rules)
+}
+
+func mainLocal() {
+	// start profiling
+
+	//f, err := os.Create("cpu.prof")
+	//
+	//if err != nil {
+	//	panic(err)
+	//}
+	//
+	//err = pprof.StartCPUProfile(f)
+	//if err != nil {
+	//	panic(err)
+	//}
+
+	// enable memory profiling
+
+	//go func() {
+	//	http.ListenAndServe(":6060", nil)
+	//}()
+
+	// stop after 10 seconds
+	//time.AfterFunc(5*time.Second, func() {
+	//	println("stopping profiling after 5 seconds")
+	//	pprof.StopCPUProfile()
+	//	f.Close()
+	//})
+
+	//defer pprof.StopCPUProfile()
+
+	board.InitAdjacentTilesCache()
+
+	state := board.InitialState()
+	state.PlayersPosition[0] = board.Coord{X: 2, Y: 6}
+
+	search.Debug(eval.RenderBoard(&state, eval.RenderOptions{Territory: true, Colors: true}))
+
+	tm := search.NewTimeManager(time.Now(), search.GetSearchLimits(10000*time.Millisecond))
+
+	bestMove, bestScore := search.FindBestMove(&state, 0, tm)
+
+	search.DebugAny("best move", bestMove)
+	search.DebugAny("best score", bestScore)
+
+}
+
+func mainCG() {
+	protocol.PlayCG(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+	"isola/protocol"
+	"isola/search"
+)
+
+func BenchmarkApp(b *testing.B) {
+	mainLocal()
+}
+
+type engineProcess struct {
+	cmd    *exec.Cmd
+	input  io.WriteCloser
+	output *bufio.Scanner
+}
+
+/**
+ * Starts an engine binary in CodinGame mode searching a fixed number of nodes per action, so that it is deterministic.
+ */
+func startEngineProcess(t *testing.T, binaryPath string) *engineProcess {
+	cmd := exec.Command(binaryPath, "-nodes", "2000", "-hash", "1")
+	cmd.Env = append(os.Environ(), "LOCAL=false")
+
+	input, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &engineProcess{cmd, input, bufio.NewScanner(output)}
+}
+
+func (e *engineProcess) play(t *testing.T, input string) string {
+	io.WriteString(e.input, input)
+
+	if !e.output.Scan() {
+		t.Fatalf("no output from %s", e.cmd.Path)
+	}
+
+	return e.output.Text()
+}
+
+func buildBinary(t *testing.T, dir string, binaryPath string) {
+	build := exec.Command("go", "build", "-o", binaryPath, ".")
+	build.Dir = dir
+
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("can't build %s: %v\n%s", dir, err, output)
+	}
+}
+
+/**
+ * The bundle fits in the size accepted by CodinGame: the comments are removed, except the header, the go: directives
+ * and the path of each bundled file.
+ */
+func TestBundleSize(t *testing.T) {
+	source, err := bundle(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := utf8.RuneCount(source); size > BUNDLE_MAX_SIZE {
+		t.Errorf("the bundle has %d characters, CodinGame accepts %d", size, BUNDLE_MAX_SIZE)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, group := range f.Comments {
+		for _, comment := range group.List {
+			if comment.Pos() > f.Package && !strings.HasPrefix(comment.Text, "//go:") && !strings.HasSuffix(comment.Text, ".go") {
+				t.Errorf("line %d: comment %q left in the bundle", fset.Position(comment.Pos()).Line, comment.Text)
+			}
+		}
+	}
+}
+
+/**
+ * Builds the engine from the packages and from the bundled file, and checks that they play the same actions in the same games.
+ */
+func TestBundlePlaysIdentically(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds two binaries")
+	}
+
+	source, err := bundle(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundleDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(bundleDir, "main.go"), source, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, "go.mod"), []byte("module bundle\n\ngo 1.20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	packagesBinary := filepath.Join(t.TempDir(), "packages")
+	bundleBinary := filepath.Join(t.TempDir(), "bundle")
+
+	buildBinary(t, ".", packagesBinary)
+	buildBinary(t, bundleDir, bundleBinary)
+
+	board.InitAdjacentTilesCache()
+
+	for _, myPlayerId := range []uint8{0, 1} {
+		t.Run(fmt.Sprintf("player %d", myPlayerId), func(t *testing.T) {
+			engines := []*engineProcess{startEngineProcess(t, packagesBinary), startEngineProcess(t, bundleBinary)}
+
+			r := protocol.NewReferee(int64(myPlayerId))
+
+			for _, engine := range engines {
+				io.WriteString(engine.input, r.GetInitialInput(myPlayerId))
+			}
+
+			for !r.IsOver() {
+				if r.State.PlayerToMove != myPlayerId {
+					legalActions := movegen.GetLegalActions(&r.State, r.State.PlayerToMove)
+					opponentAction := legalActions[r.Random.Intn(len(legalActions))]
+
+					if err := r.Play(board.FormatAction(&opponentAction)); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+
+				turnInput := r.GetTurnInput()
+
+				packagesOutput := engines[0].play(t, turnInput)
+				bundleOutput := engines[1].play(t, turnInput)
+
+				if packagesOutput != bundleOutput {
+					t.Fatalf("turn %d in %s: the packages play %q, the bundle %q", r.State.Turn, board.FormatPosition(&r.State), packagesOutput, bundleOutput)
+				}
+
+				if err := r.Play(packagesOutput); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, engine := range engines {
+				engine.input.Close()
+				if err := engine.cmd.Wait(); err != nil {
+					t.Errorf("%s: %v", engine.cmd.Path, err)
+				}
+			}
+		})
+	}
+}
+
+/**
+ * Each player finds every position of its first plies in the book whatever the opponent plays,
+ * including the symmetric positions sharing an entry, with a legal action.
+ */
+func TestBuildBook(t *testing.T) {
+	config, err := board.ParseConfig("5x5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := board.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+
+	limits := search.SearchLimits{Depth: 2}
+
+	b, stats := buildBook(3, limits, io.Discard)
+	if stats.minDepth != 2 || stats.maxDepth != 2 || stats.nodes == 0 {
+		t.Errorf("searched to a depth of %d to %d plies with %d nodes, want 2 plies", stats.minDepth, stats.maxDepth, stats.nodes)
+	}
+
+	initial := board.InitialState()
+	positions := []board.State{initial}
+	for _, a := range movegen.GetLegalActions(&initial, 0) {
+		firstState := board.ApplyAction(&initial, &a)
+		positions = append(positions, firstState)
+	}
+	bookAction, ok := b.Lookup(&initial)
+	if !ok {
+		t.Fatal("no action for the initial position")
+	}
+	afterBookState := board.ApplyAction(&initial, &bookAction)
+	for _, a := range movegen.GetLegalActions(&afterBookState, 1) {
+		positions = append(positions, board.ApplyAction(&afterBookState, &a))
+	}
+
+	for i := range positions {
+		a, ok := b.Lookup(&positions[i])
+		if !ok {
+			t.Fatalf("%s isn't in the book", board.FormatPosition(&positions[i]))
+		}
+		if err := board.ValidateAction(&positions[i], &a, positions[i].PlayerToMove); err != nil {
+			t.Fatalf("%s in %s: %v", board.FormatAction(&a), board.FormatPosition(&positions[i]), err)
+		}
+	}
+
+	// the symmetric positions share their entries
+	if len(b.Entries) >= len(positions) {
+		t.Errorf("%d entries for %d positions", len(b.Entries), len(positions))
+	}
+
+	var source bytes.Buffer
+	if err := writeBookGo(&source, b, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(source.String(), "0x"); got != len(b.Entries) {
+		t.Errorf("%d entries written, want %d", got, len(b.Entries))
+	}
+
+	// another board doesn't use the book
+	if err := board.SetConfig(board.NewConfig(7, 5)); err != nil {
+		t.Fatal(err)
+	}
+	initial = board.InitialState()
+	if a, ok := b.Lookup(&initial); ok {
+		t.Errorf("%s played on another board", board.FormatAction(&a))
+	}
+}
+
+/**
+ * A recorded game is read with its comments and empty lines, and the first illegal or malformed action is reported with its line.
+ */
+func TestReadGame(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	actions, err := readGame(strings.NewReader("# a short game\n1 4 4 4\n\n7 4 3 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[1] != (board.Action{MovePosition: board.Coord{X: 7, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}) {
+		t.Errorf("got %v", actions)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"malformed", "1 4 4 4\n7 4 3\n", 2},
+		{"pawn jumping", "1 4 4 4\n\n7 4 3 3\n5 5 0 0\n", 4},
+		{"tile removed twice", "1 4 4 4\n7 4 4 4\n", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wantLine := fmt.Sprintf("line %d:", test.line)
+			if _, err := readGame(strings.NewReader(test.input)); err == nil || !strings.HasPrefix(err.Error(), wantLine) {
+				t.Errorf("error %v, want one starting with %q", err, wantLine)
+			}
+		})
+	}
+}
+
+/**
+ * The frame of each ply draws the board with the removed tiles, the pawns, and the last action with its arrow and crosses.
+ */
+func TestWriteSVGAndGameHTML(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	actions, err := readGame(strings.NewReader("1 4 4 4\n7 4 3 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := replayGame(actions)
+
+	var svg bytes.Buffer
+	writeSVG(&svg, getGameFrame(states, actions, 2), true)
+
+	for _, want := range []struct {
+		element string
+		count   int
+	}{
+		{"<svg ", 1},
+		{"<title>ply 2: player 1 plays 7 4 3 3</title>", 1},
+		{`<rect x="20" y="20" width="40" height="40" fill="` + SVG_COLOR_TERRITORY_0 + `"`, 1},
+		{`fill="` + SVG_COLOR_REMOVED + `"`, 2},
+		{"<circle ", 2},
+		{"<line ", 1},
+		// the cross on the removed tile
+		{`stroke="` + SVG_COLOR_LAST_ACTION + `" stroke-width="3"/>`, 1},
+		{"</svg>", 1},
+	} {
+		if count := strings.Count(svg.String(), want.element); count != want.count {
+			t.Errorf("%d times %q in the frame of ply 2, want %d:\n%s", count, want.element, want.count, svg.String())
+		}
+	}
+
+	var html bytes.Buffer
+	writeGameHTML(&html, states, actions, false)
+
+	if count := strings.Count(html.String(), `<div class="frame"`); count != len(states) {
+		t.Errorf("%d frames, want %d", count, len(states))
+	}
+	if !strings.Contains(html.String(), `max="2"`) || !strings.Contains(html.String(), `data-label="ply 0: start"`) {
+		t.Errorf("no slider over the 2 plies or no start frame:\n%s", html.String())
+	}
+}
+
+/**
+ * A scripted game against the engine: the moves are listed, the malformed and illegal actions are rejected with their reason,
+ * a hint is given, and undo takes back the action of the human with the reply of the engine.
+ */
+func TestPlayGame(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	initialState := board.InitialState()
+	illegalAction := board.Action{MovePosition: board.Coord{X: 5, Y: 5}, RemoveTile: board.Coord{X: 0, Y: 0}}
+	illegalError := board.ValidateAction(&initialState, &illegalAction, 0)
+	if illegalError == nil {
+		t.Fatal("5 5 0 0 is legal")
+	}
+
+	script := []string{"moves", "9 9 9 9", "5 5 0 0", "hint", "1 4 4 4", "undo", "undo", "quit"}
+
+	var out bytes.Buffer
+	if err := playGame(strings.NewReader(strings.Join(script, "\n")+"\n"), &out, 0, 20*time.Millisecond, false); err != nil {
+		t.Fatal(err)
+	}
+	output := out.String()
+
+	initialBoard := eval.RenderBoard(&initialState, eval.RenderOptions{Territory: true})
+
+	for _, want := range []struct {
+		text  string
+		count int
+	}{
+		{", with 79 choices of removed tiles\n", 5},
+		{"move to 1 3, with ", 1},
+		{`invalid coordinate "9"`, 1},
+		{illegalError.Error() + "\n", 1},
+		{"hint: ", 1},
+		{"engine plays ", 1},
+		// the board after the undo is the initial one, and there is nothing left to undo
+		{initialBoard + "player 0> nothing to undo\n", 1},
+	} {
+		if count := strings.Count(output, want.text); count != want.count {
+			t.Errorf("%d times %q in the output, want %d:\n%s", count, want.text, want.count, output)
+		}
+	}
+}
+
+/**
+ * The analysis prints a line per best root action with its score, the depth reached and a principal variation starting with it,
+ * and says when the player to move has no legal action.
+ */
+func TestAnalyzePosition(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	currentState, err := board.ParsePosition("........./........./..#....../........./A.......B/........./......#../........./......... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	analyzePosition(&out, &currentState, 3, search.SearchLimits{Depth: 2})
+
+	resultLine := regexp.MustCompile(`(?m)^ *(\d+)\. (\d+ \d+ \d+ \d+)  score +(-?\d+)  depth +(\d+)  pv (.*)$`)
+	matches := resultLine.FindAllStringSubmatch(out.String(), -1)
+	if len(matches) != 3 {
+		t.Fatalf("%d result lines, want 3:\n%s", len(matches), out.String())
+	}
+
+	previousScore := 0
+	for i, match := range matches {
+		a, err := board.ParseAction(match[2])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := board.ValidateAction(&currentState, &a, 0); err != nil {
+			t.Errorf("line %s: %v", match[1], err)
+		}
+		score, _ := strconv.Atoi(match[3])
+		if i > 0 && score > previousScore {
+			t.Errorf("line %s: score %d after %d", match[1], score, previousScore)
+		}
+		previousScore = score
+		if match[4] != "2" {
+			t.Errorf("line %s: depth %s, want 2", match[1], match[4])
+		}
+		if !strings.HasPrefix(match[5], match[2]) {
+			t.Errorf("line %s: pv %q doesn't start with %s", match[1], match[5], match[2])
+		}
+	}
+
+	stuckState, err := board.ParsePosition("A#......./##......./........./........./........B/........./........./........./......... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	analyzePosition(&out, &stuckState, 0, search.SearchLimits{Depth: 2})
+
+	if !strings.HasSuffix(out.String(), "player 0 to move\nno legal action for player 0\n") {
+		t.Errorf("got %q, want no legal action for player 0", out.String())
+	}
+}
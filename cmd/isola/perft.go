@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"isola/board"
+	"isola/movegen"
+	"isola/search"
+)
+
+func init() {
+	commands["perft"] = perftCommand
+}
+
+/**
+ * Counts the leaf nodes of the game tree of a position to a given depth, to check and measure the move generator.
+ */
+func perftCommand(args []string) error {
+	flags := flag.NewFlagSet("perft", flag.ContinueOnError)
+	position := flags.String("position", "", "root position, see board.FormatPosition (default the initial position)")
+	boardFlags := addBoardFlags(flags)
+	depth := flags.Int("depth", 2, "depth of the leaf nodes")
+	divide := flags.Bool("divide", false, "print the leaf nodes count of each root action")
+	full := flags.Bool("full", false, "generate every legal removal instead of the ones searched by the engine")
+	reference := flags.Bool("reference", false, "count with the slow reference generator")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *depth < 1 || *depth > search.MAX_PLY {
+		return fmt.Errorf("invalid depth %d", *depth)
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	currentState := board.InitialState()
+	if *position != "" {
+		var err error
+		if currentState, err = board.ParsePosition(*position); err != nil {
+			return err
+		}
+	}
+
+	movegen.AllowNotNeighbor = *full
+
+	startedAt := time.Now()
+
+	nodes := 0
+	if *divide {
+		for _, result := range movegen.PerftDivide(&currentState, *depth, *reference) {
+			fmt.Fprintf(os.Stdout, "%s: %d\n", board.FormatAction(&result.Action), result.Nodes)
+			nodes += result.Nodes
+		}
+	} else if *reference {
+		nodes = movegen.ReferencePerft(&currentState, *depth)
+	} else {
+		nodes = movegen.Perft(&currentState, *depth, 0)
+	}
+
+	duration := time.Since(startedAt)
+	fmt.Fprintf(os.Stdout, "nodes: %d, time: %v, nodes/s: %.0f\n", nodes, duration, float64(nodes)/duration.Seconds())
+
+	return nil
+}
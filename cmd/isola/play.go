@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+	"isola/search"
+)
+
+func init() {
+	commands["play"] = playCommand
+}
+
+const PLAY_HELP = `commands:
+  x y x y   move your pawn to the first coordinates and remove the tile at the second ones
+            (x y x y x y to remove two tiles with removals=2)
+  moves     list the tiles your pawn can move to
+  hint      ask the engine for the best action
+  undo      take back your last action
+  quit      leave the game`
+
+/**
+ * Plays a game in the terminal between a human and the engine, which plays all the other players.
+ */
+func playCommand(args []string) error {
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	side := flags.Int("side", 0, "player controlled by the human, 0 starts on the left and plays first, then 1, 2 and 3")
+	boardFlags := addBoardFlags(flags)
+	moveTime := flags.Duration("time", 1000*time.Millisecond, "engine time per action")
+	colors := flags.Bool("colors", true, "use ANSI colors")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	if *side < 0 || *side >= int(board.PlayersCount) {
+		return fmt.Errorf("invalid side %d", *side)
+	}
+
+	return playGame(os.Stdin, os.Stdout, uint8(*side), *moveTime, *colors)
+}
+
+func playGame(in io.Reader, out io.Writer, humanPlayerId uint8, moveTime time.Duration, colors bool) error {
+	history := []board.State{board.InitialState()}
+	actions := make([]board.Action, 0)
+
+	scanner := bufio.NewScanner(in)
+
+	fmt.Fprintln(out, PLAY_HELP)
+
+	for {
+		currentState := &history[len(history)-1]
+
+		// the state is replaced, the eliminations are taken back with the action before them
+		for board.GetActivePlayersCount(currentState) > 2 && !movegen.CanPlay(currentState, currentState.PlayerToMove) {
+			if currentState.PlayerToMove == humanPlayerId {
+				fmt.Fprint(out, "\n"+eval.RenderBoard(currentState, eval.RenderOptions{Territory: true, Colors: colors}))
+				fmt.Fprintln(out, "you can't play, you are eliminated")
+				return nil
+			}
+			fmt.Fprintf(out, "player %d can't play and is eliminated\n", currentState.PlayerToMove)
+			*currentState = board.EliminatePlayer(currentState)
+		}
+
+		playerId := currentState.PlayerToMove
+
+		var lastAction *board.Action
+		if len(actions) > 0 {
+			lastAction = &actions[len(actions)-1]
+		}
+
+		fmt.Fprint(out, "\n"+eval.RenderBoard(currentState, eval.RenderOptions{LastAction: lastAction, Territory: true, Colors: colors}))
+
+		if !movegen.CanPlay(currentState, playerId) {
+			switch winnerId := board.GetNextPlayer(currentState, playerId); {
+			case playerId == humanPlayerId:
+				fmt.Fprintln(out, "you can't play, the engine wins")
+			case winnerId == humanPlayerId:
+				fmt.Fprintln(out, "the engine can't play, you win")
+			default:
+				fmt.Fprintf(out, "player %d can't play, player %d wins\n", playerId, winnerId)
+			}
+			return nil
+		}
+
+		if playerId != humanPlayerId {
+			bestAction, bestScore := search.FindBestMove(currentState, playerId, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: moveTime}))
+
+			if bestAction == nil {
+				return fmt.Errorf("the engine found no action")
+			}
+			if err := board.ValidateAction(currentState, bestAction, playerId); err != nil {
+				return fmt.Errorf("the engine played an illegal action: %w", err)
+			}
+
+			fmt.Fprintf(out, "engine plays %s (score %d)\n", board.FormatAction(bestAction), bestScore)
+
+			history = append(history, board.ApplyAction(currentState, bestAction))
+			actions = append(actions, *bestAction)
+			continue
+		}
+
+		for {
+			fmt.Fprintf(out, "player %d> ", humanPlayerId)
+
+			if !scanner.Scan() {
+				return scanner.Err()
+			}
+
+			input := strings.TrimSpace(scanner.Text())
+
+			switch input {
+			case "":
+				continue
+			case "quit":
+				return nil
+			case "help":
+				fmt.Fprintln(out, PLAY_HELP)
+				continue
+			case "moves":
+				showLegalMoves(out, currentState, playerId)
+				continue
+			case "hint":
+				hintAction, hintScore := search.FindBestMove(currentState, playerId, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: moveTime}))
+				if hintAction == nil {
+					fmt.Fprintln(out, "no hint found")
+				} else {
+					fmt.Fprintf(out, "hint: %s (score %d)\n", board.FormatAction(hintAction), hintScore)
+				}
+				continue
+			case "undo":
+				// take back the engine replies too, so that it is the human's turn again
+				lastHumanPly := len(actions) - 1
+				for lastHumanPly >= 0 && history[lastHumanPly].PlayerToMove != humanPlayerId {
+					lastHumanPly--
+				}
+				if lastHumanPly < 0 {
+					fmt.Fprintln(out, "nothing to undo")
+					continue
+				}
+				history = history[:lastHumanPly+1]
+				actions = actions[:lastHumanPly]
+			default:
+				a, err := board.ParseAction(input)
+				if err != nil {
+					fmt.Fprintln(out, err)
+					continue
+				}
+
+				if err := board.ValidateAction(currentState, &a, playerId); err != nil {
+					fmt.Fprintln(out, err)
+					continue
+				}
+
+				history = append(history, board.ApplyAction(currentState, &a))
+				actions = append(actions, a)
+			}
+
+			break
+		}
+	}
+}
+
+func showLegalMoves(out io.Writer, currentState *board.State, playerId uint8) {
+	movePositions := make([]board.Coord, 0, board.MAX_MOVES)
+	removalsCounts := map[board.Coord]int{}
+
+	for _, a := range movegen.GetLegalActions(currentState, playerId) {
+		if removalsCounts[a.MovePosition] == 0 {
+			movePositions = append(movePositions, a.MovePosition)
+		}
+		removalsCounts[a.MovePosition]++
+	}
+
+	for _, movePosition := range movePositions {
+		fmt.Fprintf(out, "move to %d %d, with %d choices of removed tiles\n", movePosition.X, movePosition.Y, removalsCounts[movePosition])
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"isola/board"
+	"isola/solver"
+)
+
+func init() {
+	commands["solve"] = solveCommand
+}
+
+/**
+ * Solves a position of a small board completely and optionally writes the result of every position solved on the way.
+ */
+func solveCommand(args []string) error {
+	flags := flag.NewFlagSet("solve", flag.ContinueOnError)
+	position := flags.String("position", "", "position to solve, see board.FormatPosition (default the initial position)")
+	boardFlags := addBoardFlags(flags)
+	outPath := flags.String("out", "", "write the table of the solved positions to this file, see solver.ResultTable.Write")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	if board.PlayersCount != 2 {
+		return fmt.Errorf("only the games of two players can be solved")
+	}
+
+	currentState := board.InitialState()
+	if *position != "" {
+		var err error
+		if currentState, err = board.ParsePosition(*position); err != nil {
+			return err
+		}
+	}
+
+	startedAt := time.Now()
+
+	s := solver.NewSolver()
+	result := s.Solve(&currentState)
+
+	duration := time.Since(startedAt)
+
+	winnerId := currentState.PlayerToMove
+	if result == solver.LOSS {
+		winnerId = 1 - winnerId
+	}
+
+	fmt.Fprintf(os.Stdout, "%s %s: player %d wins\n", board.FormatConfig(board.GetConfig()), board.FormatRules(board.GameRules), winnerId)
+	if winningAction := s.GetWinningAction(&currentState); winningAction != nil {
+		fmt.Fprintf(os.Stdout, "winning action: %s\n", board.FormatAction(winningAction))
+	}
+	fmt.Fprintf(os.Stdout, "positions: %d, time: %v, positions/s: %.0f\n", s.Nodes, duration, float64(s.Nodes)/duration.Seconds())
+
+	if *outPath == "" {
+		return nil
+	}
+
+	f, err := os.Create(*outPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := s.Table.Write(f); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "%d positions written to %s\n", s.Table.Len(), *outPath)
+	return f.Close()
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+)
+
+func init() {
+	commands["svg"] = svgCommand
+}
+
+const SVG_TILE_SIZE = 40
+const SVG_MARGIN = 20
+
+const SVG_COLOR_FREE = "#f2f2f2"
+const SVG_COLOR_REMOVED = "#3a3a3a"
+const SVG_COLOR_TERRITORY_0 = "#f6c6c6"
+const SVG_COLOR_TERRITORY_1 = "#c6d4f6"
+const SVG_COLOR_TERRITORY_2 = "#c6ecc6"
+const SVG_COLOR_TERRITORY_3 = "#e6c6f0"
+const SVG_COLOR_TERRITORY_BOTH = "#f3e6a8"
+const SVG_COLOR_PLAYER_0 = "#d33"
+const SVG_COLOR_PLAYER_1 = "#36c"
+const SVG_COLOR_PLAYER_2 = "#393"
+const SVG_COLOR_PLAYER_3 = "#939"
+const SVG_COLOR_ELIMINATED = "#888"
+const SVG_COLOR_LAST_ACTION = "#f80"
+
+/**
+ * Exports a recorded game as an HTML file with one SVG frame per ply, or a single position as an SVG file.
+ */
+func svgCommand(args []string) error {
+	flags := flag.NewFlagSet("svg", flag.ContinueOnError)
+	gamePath := flags.String("game", "", "recorded game, one \"x y x y\" action per line (default stdin)")
+	outPath := flags.String("out", "", "output file (default stdout)")
+	ply := flags.Int("ply", -1, "export the position after this many plies as a single SVG instead of the whole game as HTML")
+	territory := flags.Bool("territory", true, "shade the tiles owned by each player")
+	boardFlags := addBoardFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := boardFlags.set(); err != nil {
+		return err
+	}
+
+	input := io.Reader(os.Stdin)
+	if *gamePath != "" {
+		f, err := os.Open(*gamePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+
+	actions, err := readGame(input)
+	if err != nil {
+		return err
+	}
+
+	states := replayGame(actions)
+
+	output := io.Writer(os.Stdout)
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if *ply > len(actions) {
+		return fmt.Errorf("the game only has %d plies", len(actions))
+	}
+
+	if *ply >= 0 {
+		writeSVG(output, getGameFrame(states, actions, *ply), *territory)
+		return nil
+	}
+
+	writeGameHTML(output, states, actions, *territory)
+	return nil
+}
+
+/**
+ * Reads a recorded game, one action per line in the output format, empty lines and lines starting with # are ignored.
+ * Each action must be legal for the player to move after the previous ones, the players who can't play being eliminated as in replayGame.
+ */
+func readGame(r io.Reader) ([]board.Action, error) {
+	actions := make([]board.Action, 0)
+	currentState := board.InitialState()
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		a, err := board.ParseAction(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		currentState = movegen.EliminateStuckPlayers(&currentState)
+		if err := board.ValidateAction(&currentState, &a, currentState.PlayerToMove); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		currentState = board.ApplyAction(&currentState, &a)
+
+		actions = append(actions, a)
+	}
+
+	return actions, scanner.Err()
+}
+
+/**
+ * Returns the states of a game from the initial state, states[i] is the state after i plies.
+ * The players who can't play are eliminated in the state before the ply, as the referee does.
+ */
+func replayGame(actions []board.Action) []board.State {
+	states := make([]board.State, 0, len(actions)+1)
+	states = append(states, board.InitialState())
+
+	for i := range actions {
+		states[i] = movegen.EliminateStuckPlayers(&states[i])
+		states = append(states, board.ApplyAction(&states[i], &actions[i]))
+	}
+	states[len(actions)] = movegen.EliminateStuckPlayers(&states[len(actions)])
+
+	return states
+}
+
+type gameFrame struct {
+	ply           int
+	state         *board.State
+	previousState *board.State
+	lastAction    *board.Action
+}
+
+func getGameFrame(states []board.State, actions []board.Action, ply int) gameFrame {
+	frame := gameFrame{ply: ply, state: &states[ply]}
+	if ply > 0 {
+		frame.previousState = &states[ply-1]
+		frame.lastAction = &actions[ply-1]
+	}
+	return frame
+}
+
+func (f gameFrame) label() string {
+	if f.lastAction == nil {
+		return "ply 0: start"
+	}
+	return fmt.Sprintf("ply %d: player %d plays %s", f.ply, f.previousState.PlayerToMove, board.FormatAction(f.lastAction))
+}
+
+func getTileCenter(position board.Coord) (int, int) {
+	return SVG_MARGIN + int(position.X)*SVG_TILE_SIZE + SVG_TILE_SIZE/2, SVG_MARGIN + int(position.Y)*SVG_TILE_SIZE + SVG_TILE_SIZE/2
+}
+
+func writeSVG(w io.Writer, frame gameFrame, territory bool) {
+	colorGrid := [board.MAX_HEIGHT][board.MAX_WIDTH]int8{}
+	if territory {
+		eval.ComputePartition(frame.state, &colorGrid)
+	}
+
+	width := 2*SVG_MARGIN + int(board.Width)*SVG_TILE_SIZE
+	height := 2*SVG_MARGIN + int(board.Height)*SVG_TILE_SIZE
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(w, `<title>%s</title>`+"\n", html.EscapeString(frame.label()))
+	fmt.Fprintf(w, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="8" refY="5" markerWidth="5" markerHeight="5" orient="auto"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker></defs>`+"\n", SVG_COLOR_LAST_ACTION)
+
+	for x := 0; x < int(board.Width); x++ {
+		fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", SVG_MARGIN+x*SVG_TILE_SIZE+SVG_TILE_SIZE/2, SVG_MARGIN-6, x)
+	}
+	for y := 0; y < int(board.Height); y++ {
+		fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle">%d</text>`+"\n", SVG_MARGIN/2, SVG_MARGIN+y*SVG_TILE_SIZE+SVG_TILE_SIZE/2, y)
+	}
+
+	for y := uint8(0); y < board.Height; y++ {
+		for x := uint8(0); x < board.Width; x++ {
+			c := board.Coord{X: x, Y: y}
+
+			fill := SVG_COLOR_FREE
+			switch {
+			case board.IsTileRemoved(frame.state, &c):
+				fill = SVG_COLOR_REMOVED
+			case colorGrid[y][x] == -1:
+				fill = SVG_COLOR_TERRITORY_0
+			case colorGrid[y][x] == 1:
+				fill = SVG_COLOR_TERRITORY_1
+			case colorGrid[y][x] == 2:
+				fill = SVG_COLOR_TERRITORY_2
+			case colorGrid[y][x] == 3:
+				fill = SVG_COLOR_TERRITORY_3
+			case colorGrid[y][x] == 42:
+				fill = SVG_COLOR_TERRITORY_BOTH
+			}
+
+			fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#999"/>`+"\n", SVG_MARGIN+int(x)*SVG_TILE_SIZE, SVG_MARGIN+int(y)*SVG_TILE_SIZE, SVG_TILE_SIZE, SVG_TILE_SIZE, fill)
+		}
+	}
+
+	if frame.lastAction != nil {
+		// cross on the removed tiles
+		for _, removedTile := range board.GetRemovedTiles(frame.lastAction) {
+			cx, cy := getTileCenter(removedTile)
+			d := SVG_TILE_SIZE/2 - 6
+			fmt.Fprintf(w, `<path d="M%d,%d L%d,%d M%d,%d L%d,%d" stroke="%s" stroke-width="3"/>`+"\n", cx-d, cy-d, cx+d, cy+d, cx-d, cy+d, cx+d, cy-d, SVG_COLOR_LAST_ACTION)
+		}
+
+		// arrow from the previous position of the pawn that moved
+		for playerId := 0; playerId < int(board.PlayersCount); playerId++ {
+			if frame.state.PlayersPosition[playerId] == frame.lastAction.MovePosition {
+				fromX, fromY := getTileCenter(frame.previousState.PlayersPosition[playerId])
+				toX, toY := getTileCenter(frame.lastAction.MovePosition)
+				fmt.Fprintf(w, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="3" marker-end="url(#arrow)"/>`+"\n", fromX, fromY, toX, toY, SVG_COLOR_LAST_ACTION)
+			}
+		}
+	}
+
+	for playerId, color := range []string{SVG_COLOR_PLAYER_0, SVG_COLOR_PLAYER_1, SVG_COLOR_PLAYER_2, SVG_COLOR_PLAYER_3}[:board.PlayersCount] {
+		if board.IsEliminated(frame.state, uint8(playerId)) {
+			color = SVG_COLOR_ELIMINATED
+		}
+		cx, cy := getTileCenter(frame.state.PlayersPosition[playerId])
+		fmt.Fprintf(w, `<circle cx="%d" cy="%d" r="%d" fill="%s" fill-opacity="0.85"/>`+"\n", cx, cy, SVG_TILE_SIZE/2-6, color)
+		fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="central" fill="white" font-weight="bold">%s</text>`+"\n", cx, cy, string(rune('A'+playerId)))
+	}
+
+	fmt.Fprintln(w, "</svg>")
+}
+
+func writeGameHTML(w io.Writer, states []board.State, actions []board.Action, territory bool) {
+	fmt.Fprintln(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Isola game</title>
+<style>
+body { font-family: sans-serif; }
+.frame { display: none; }
+.frame.current { display: block; }
+</style>
+</head>
+<body>`)
+	fmt.Fprintf(w, `<div><button id="previous">&lt;</button> <input id="slider" type="range" min="0" max="%d" value="0"> <button id="next">&gt;</button> <span id="label"></span></div>`+"\n", len(actions))
+
+	for ply := range states {
+		frame := getGameFrame(states, actions, ply)
+		fmt.Fprintf(w, `<div class="frame" data-label="%s">`+"\n", html.EscapeString(frame.label()))
+		writeSVG(w, frame, territory)
+		fmt.Fprintln(w, "</div>")
+	}
+
+	fmt.Fprintln(w, `<script>
+const frames = document.querySelectorAll(".frame");
+const slider = document.getElementById("slider");
+const label = document.getElementById("label");
+function show(ply) {
+	ply = Math.max(0, Math.min(frames.length - 1, ply));
+	frames.forEach((frame, i) => frame.classList.toggle("current", i === ply));
+	slider.value = ply;
+	label.textContent = frames[ply].dataset.label;
+}
+slider.addEventListener("input", () => show(Number(slider.value)));
+document.getElementById("previous").addEventListener("click", () => show(Number(slider.value) - 1));
+document.getElementById("next").addEventListener("click", () => show(Number(slider.value) + 1));
+document.addEventListener("keydown", (e) => {
+	if (e.key === "ArrowLeft") show(Number(slider.value) - 1);
+	if (e.key === "ArrowRight") show(Number(slider.value) + 1);
+});
+show(0);
+</script>
+</body>
+</html>`)
+}
@@ -0,0 +1,424 @@
+package eval
+
+import (
+	"fmt"
+
+	"isola/board"
+	"isola/movegen"
+)
+
+func assert(condition bool, message string) {
+	if !condition {
+		panic(message)
+	}
+}
+
+func assertEqual(expected interface{}, actual interface{}, message string) {
+	if expected != actual {
+		panic(fmt.Sprintf("%v != %v: %v", expected, actual, message))
+	}
+}
+
+var distanceFromPlayer [2][board.MAX_GRID_SIZE]int
+
+// with more than two players, the bonus of each eliminated opponent, half of it for an opponent who can't play yet
+const ELIMINATION_BONUS = 1000000 / 8
+
+/**
+ * The score of the state for myPlayerId against all the other players, the game is over when it can't play on its turn.
+ * With more than two players, the mobility and cells of the opponents are summed, and the end of the game
+ * is scored as with two players once a single opponent is left.
+ */
+func GetScore(currentState *board.State, myPlayerId uint8, currentPlayerId uint8) int {
+	myPossibleActions := movegen.GetPossibleActionsCount(currentState, myPlayerId)
+
+	// a player who can't play a whole turn of the rules loses, even if it can move
+	hasTilesToRemove := movegen.HasTilesToRemove(currentState)
+	myCanPlay := myPossibleActions > 0 && hasTilesToRemove
+
+	opponentPossibleActions := 0
+	opponentCanPlay := true
+	activeOpponentsCount := 0
+	bonusElimination := 0
+
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if playerId == myPlayerId {
+			continue
+		}
+		if board.IsEliminated(currentState, playerId) {
+			bonusElimination += ELIMINATION_BONUS
+			continue
+		}
+
+		possibleActions := movegen.GetPossibleActionsCount(currentState, playerId)
+		opponentPossibleActions += possibleActions
+		activeOpponentsCount++
+
+		if possibleActions == 0 || !hasTilesToRemove {
+			opponentCanPlay = false
+			bonusElimination += ELIMINATION_BONUS / 2
+		}
+	}
+
+	// the end of the game is only near once a single opponent is left
+	if activeOpponentsCount > 1 {
+		opponentCanPlay = true
+	} else {
+		bonusElimination = 0
+	}
+
+	// a good action is a action that maximize my player closest coords and minimize opponent closest coords
+	myPlayerCellsCount, opponentCellsCount := CountPartitionCells(currentState, myPlayerId)
+
+	// old for check
+	//myPlayerCellsCountOld, opponentCellsCountOld := countPartitionCellsOld(currentState, myPlayerId)
+
+	//debug(fmt.Sprintf("state %v with removed: %v", currentState, currentState.boardRemoved.show()))
+
+	//assertEqual(myPlayerCellsCount, myPlayerCellsCountOld, "myPlayerCellsCount != myPlayerCellsCountOld")
+	//assertEqual(opponentCellsCount, opponentCellsCountOld, "opponentCellsCount != opponentCellsCountOld")
+
+	bonusEnd := 0
+
+	myTurn := myPlayerId == currentPlayerId
+
+	if !opponentCanPlay && !myTurn {
+		bonusEnd += 1000000
+		bonusEnd -= int(currentState.Turn) * 1000
+	} else if !opponentCanPlay {
+		bonusEnd += 1000000 / 2
+	}
+
+	if !myCanPlay && myTurn {
+		bonusEnd -= 1000000
+		bonusEnd += int(currentState.Turn) * 1000
+	} else if !myCanPlay {
+		bonusEnd -= 1000000 / 2
+	}
+
+	return bonusEnd + bonusElimination + myPlayerCellsCount - opponentCellsCount + 256*myPossibleActions - 256*opponentPossibleActions
+}
+
+/**
+ * A fast score of the state for myPlayerId, from the mobility of the players only.
+ */
+func GetScorePossibleAction(currentState *board.State, myPlayerId uint8) int {
+	myPossibleActions := movegen.GetPossibleActionsCount(currentState, myPlayerId)
+	opponentPossibleActions := 0
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if playerId != myPlayerId && !board.IsEliminated(currentState, playerId) {
+			opponentPossibleActions += movegen.GetPossibleActionsCount(currentState, playerId)
+		}
+	}
+
+	bonusEnd := 0
+	hasTilesToRemove := movegen.HasTilesToRemove(currentState)
+
+	if opponentPossibleActions == 0 || !hasTilesToRemove {
+		bonusEnd += 1000000
+		bonusEnd -= int(currentState.Turn) * 1000
+	}
+
+	if myPossibleActions == 0 || !hasTilesToRemove {
+		bonusEnd -= 1000000
+		bonusEnd += int(currentState.Turn) * 1000
+	}
+
+	return bonusEnd + 10*myPossibleActions - 10*opponentPossibleActions
+}
+
+func countPartitionCellsOld(currentState *board.State, myPlayerId uint8) (int, int) {
+	// we use a BFS to find all the tiles that are reachable from a player
+
+	for i := 0; i < board.MAX_GRID_SIZE; i++ {
+		distanceFromPlayer[0][i] = -1
+		distanceFromPlayer[1][i] = -1
+	}
+
+	// for each player, find the distance to each tile using BFS
+	for playerId := 0; playerId < 2; playerId++ {
+		distanceFromPlayer[playerId][board.TileIndex(currentState.PlayersPosition[playerId])] = 0
+
+		queue := make([]board.Coord, 0, board.MAX_GRID_SIZE)
+		queue = append(queue, currentState.PlayersPosition[playerId])
+
+		for len(queue) > 0 {
+			currentPosition := queue[0]
+			queue = queue[1:]
+
+			// for each adjacent tile, if it is not occupied and not already visited, add it to the queue
+			adjacentTiles := board.GetAdjacentTiles(currentPosition)
+			for _, adj := range *adjacentTiles {
+				tileIndex := board.TileIndex(adj)
+
+				if !board.IsTileOccupied(currentState, &adj) && !board.IsTileRemoved(currentState, &adj) && distanceFromPlayer[playerId][tileIndex] == -1 {
+					distanceFromPlayer[playerId][tileIndex] = distanceFromPlayer[playerId][board.TileIndex(currentPosition)] + 1
+					queue = append(queue, adj)
+				}
+			}
+		}
+	}
+
+	myPlayerCellsCount := 0
+	opponentCellsCount := 0
+
+	// for each tile, find the closest player
+	for y := uint8(0); y < board.Height; y++ {
+		for x := uint8(0); x < board.Width; x++ {
+			tileIndex := board.TileIndex(board.Coord{X: x, Y: y})
+
+			if distanceFromPlayer[0][tileIndex] == -1 && distanceFromPlayer[1][tileIndex] == -1 {
+				// if the tile is not reachable by any player, it is not part of the partition
+				continue
+			}
+
+			playerToOwn := -1
+
+			if distanceFromPlayer[0][tileIndex] == -1 && distanceFromPlayer[1][tileIndex] != -1 {
+				playerToOwn = 1
+			} else if distanceFromPlayer[0][tileIndex] != -1 && distanceFromPlayer[1][tileIndex] == -1 {
+				playerToOwn = 0
+			} else if distanceFromPlayer[0][tileIndex] < distanceFromPlayer[1][tileIndex] {
+				playerToOwn = 0
+			} else if distanceFromPlayer[0][tileIndex] > distanceFromPlayer[1][tileIndex] {
+				playerToOwn = 1
+			}
+
+			if playerToOwn == -1 {
+				// if the tile is reachable by both players at the same distance, it is not part of the partition
+				continue
+			} else if playerToOwn == int(myPlayerId) {
+				myPlayerCellsCount++
+			} else if playerToOwn == int(1-myPlayerId) {
+				opponentCellsCount++
+			}
+		}
+	}
+
+	return myPlayerCellsCount, opponentCellsCount
+}
+
+var discovered = [board.MAX_PLAYERS][]board.Coord{}
+
+var newDiscovered = [board.MAX_PLAYERS][]board.Coord{}
+
+func init() {
+	for playerId := range discovered {
+		discovered[playerId] = make([]board.Coord, 0, board.MAX_GRID_SIZE)
+		newDiscovered[playerId] = make([]board.Coord, 0, board.MAX_GRID_SIZE)
+	}
+}
+
+/**
+ * The cells count of myPlayerId and the sum of the cells counts of the other players.
+ */
+func CountPartitionCells(currentState *board.State, myPlayerId uint8) (int, int) {
+	colorGrid := [board.MAX_HEIGHT][board.MAX_WIDTH]int8{}
+	cellsCounts := ComputePartition(currentState, &colorGrid)
+
+	opponentCellsCount := 0
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if playerId != myPlayerId {
+			opponentCellsCount += cellsCounts[playerId]
+		}
+	}
+
+	return cellsCounts[myPlayerId], opponentCellsCount
+}
+
+/**
+ * Fills colorGrid with the owner of each tile reachable by a player, as given by getColorForPlayer, and returns the cells count of each player.
+ * The eliminated players own no tile, their pawns are obstacles.
+ */
+func ComputePartition(currentState *board.State, colorGrid *[board.MAX_HEIGHT][board.MAX_WIDTH]int8) [board.MAX_PLAYERS]int {
+	// we use a BFS to find all the tiles that are reachable from a player
+
+	// -1 for first player
+	// 1 for second player
+	// 2 and 3 for the third and fourth players
+	// 42 for several players
+	// 0 for no player
+
+	cellsCounts := [board.MAX_PLAYERS]int{}
+	remaining := 0
+
+	// the tiles of the active pawns are colored below, the other obstacles are the removed tiles and the eliminated pawns
+	obstacles := currentState.BoardRemoved
+
+	// the frontiers are preallocated, a tile is discovered at most once by each player at each step
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		discovered[playerId] = discovered[playerId][:0]
+		if board.IsEliminated(currentState, playerId) {
+			obstacles.Set(board.TileIndex(currentState.PlayersPosition[playerId]), true)
+			continue
+		}
+
+		position := currentState.PlayersPosition[playerId]
+		discovered[playerId] = append(discovered[playerId], position)
+		colorGrid[position.Y][position.X] = getColorForPlayer(int(playerId))
+		cellsCounts[playerId] = 1
+		remaining++
+	}
+
+	for remaining > 0 {
+
+		//debugAny("start of loop discovered", discovered)
+
+		// the players who discovered each tile at this step
+		newDiscoveredGrid := [board.MAX_HEIGHT][board.MAX_WIDTH]uint8{}
+
+		for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+			// reset the new discovered tiles
+			newDiscovered[playerId] = newDiscovered[playerId][:0]
+
+			for _, position := range discovered[playerId] {
+
+				adjacentTiles := board.GetAdjacentTiles(position)
+				for _, adj := range *adjacentTiles {
+					if colorGrid[adj.Y][adj.X] == 0 && newDiscoveredGrid[adj.Y][adj.X]&(1<<playerId) == 0 && !obstacles.Get(board.TileIndex(adj)) {
+						newDiscovered[playerId] = append(newDiscovered[playerId], adj)
+						newDiscoveredGrid[adj.Y][adj.X] |= 1 << playerId
+					}
+				}
+			}
+		}
+
+		// the tiles discovered by a single player are assigned to this player, the others are contested
+		remaining = 0
+		for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+			for _, position := range newDiscovered[playerId] {
+				if colorGrid[position.Y][position.X] != 0 {
+					continue
+				}
+				if newDiscoveredGrid[position.Y][position.X] != 1<<playerId {
+					colorGrid[position.Y][position.X] = 42
+					continue
+				}
+				colorGrid[position.Y][position.X] = getColorForPlayer(int(playerId))
+				cellsCounts[playerId]++
+			}
+
+			discovered[playerId], newDiscovered[playerId] = newDiscovered[playerId], discovered[playerId]
+			remaining += len(discovered[playerId])
+		}
+
+		//debugAny("discovered", discovered)
+
+	}
+
+	//debugAny("colorGrid", showColorGrid(*colorGrid))
+
+	return cellsCounts
+}
+
+func showColorGrid(colorGrid [board.MAX_HEIGHT][board.MAX_WIDTH]int8) string {
+	var result string
+
+	for y := 0; y < int(board.Height); y++ {
+		for x := 0; x < int(board.Width); x++ {
+			if colorGrid[y][x] == -1 {
+				result += "0"
+			} else if colorGrid[y][x] == 1 || colorGrid[y][x] == 2 || colorGrid[y][x] == 3 {
+				result += fmt.Sprint(colorGrid[y][x])
+			} else if colorGrid[y][x] == 42 {
+				result += "B"
+			} else {
+				result += "."
+			}
+		}
+		result += "\n"
+	}
+
+	return result
+}
+
+func intersection(a []board.Coord, b []board.Coord) []board.Coord {
+	var result []board.Coord
+
+	for _, aCoord := range a {
+		for _, bCoord := range b {
+			if aCoord.X == bCoord.X && aCoord.Y == bCoord.Y {
+				result = append(result, aCoord)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func getColorForPlayer(playerId int) int8 {
+	color := int8(0)
+	if playerId == 0 {
+		color = -1
+	} else {
+		color = int8(playerId)
+	}
+	return color
+}
+
+// the depth first search of GetSeparatingTiles: the order of discovery of each tile from 1, 0 when not discovered,
+// the lowest order reachable from its subtree with one back edge, and its parent in the search tree
+var discoveryOrder [board.MAX_GRID_SIZE]uint16
+var lowestOrder [board.MAX_GRID_SIZE]uint16
+var searchParent [board.MAX_GRID_SIZE]board.Coord
+var discoveredCount uint16
+
+/**
+ * The free tiles whose removal alone would separate the pawns of playerId and opponentId, none if they are already separated:
+ * the articulation points of the free tiles between the two pawns, the pawns of the other players are obstacles.
+ */
+func GetSeparatingTiles(currentState *board.State, playerId uint8, opponentId uint8) (separatingTiles board.CompactBoolArray) {
+	discoveryOrder = [board.MAX_GRID_SIZE]uint16{}
+	discoveredCount = 0
+
+	position := currentState.PlayersPosition[playerId]
+	opponentPosition := currentState.PlayersPosition[opponentId]
+
+	searchSeparatingTiles(currentState, position, opponentPosition)
+
+	if discoveryOrder[board.TileIndex(opponentPosition)] == 0 {
+		return separatingTiles
+	}
+
+	// a tile of the path of the search tree between the pawns separates them if the subtree of its child has no back edge above it
+	for child, tile := opponentPosition, searchParent[board.TileIndex(opponentPosition)]; tile != position; child, tile = tile, searchParent[board.TileIndex(tile)] {
+		if lowestOrder[board.TileIndex(child)] >= discoveryOrder[board.TileIndex(tile)] {
+			separatingTiles.Set(board.TileIndex(tile), true)
+		}
+	}
+
+	return separatingTiles
+}
+
+/**
+ * Tarjan's depth first search of the articulation points from tile, through the free tiles and the tile of the opponent.
+ */
+func searchSeparatingTiles(currentState *board.State, tile board.Coord, opponentPosition board.Coord) {
+	tileIndex := board.TileIndex(tile)
+	discoveredCount++
+	discoveryOrder[tileIndex] = discoveredCount
+	lowestOrder[tileIndex] = discoveredCount
+
+	for _, adj := range *board.GetAdjacentTiles(tile) {
+		adjIndex := board.TileIndex(adj)
+
+		// the edge to the parent only gives its own order, which doesn't change the test of the separating tiles
+		if discoveryOrder[adjIndex] != 0 {
+			if discoveryOrder[adjIndex] < lowestOrder[tileIndex] {
+				lowestOrder[tileIndex] = discoveryOrder[adjIndex]
+			}
+			continue
+		}
+
+		if adj != opponentPosition && !board.IsTileFree(currentState, &adj) {
+			continue
+		}
+
+		searchParent[adjIndex] = tile
+		searchSeparatingTiles(currentState, adj, opponentPosition)
+		if lowestOrder[adjIndex] < lowestOrder[tileIndex] {
+			lowestOrder[tileIndex] = lowestOrder[adjIndex]
+		}
+	}
+}
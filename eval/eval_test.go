@@ -0,0 +1,260 @@
+package eval
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"isola/board"
+	"isola/movegen"
+)
+
+func TestScoreOfTheEndOfTheGame(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	// A is blocked in the top left corner
+	blocked := "A#......./##......./........./........./........./........./........./........./........B"
+
+	tests := []struct {
+		name       string
+		position   string
+		myPlayerId uint8
+		// 1 for a win, -1 for a loss, 0 when the game goes on
+		want int
+	}{
+		{"blocked player to move loses", blocked + " 0", 0, -1},
+		{"opponent of the blocked player to move wins", blocked + " 0", 1, 1},
+		{"blocked player loses once the opponent has played", blocked + " 1", 0, -1},
+		{"opponent wins once it has played", blocked + " 1", 1, 1},
+		{"initial position", "........./........./........./........./A.......B/........./........./........./......... 0", 0, 0},
+		{"initial position for player 1", "........./........./........./........./A.......B/........./........./........./......... 0", 1, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currentState, err := board.ParsePosition(test.position)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			score := GetScore(&currentState, test.myPlayerId, currentState.PlayerToMove)
+
+			got := 0
+			if score > 1000000/4 {
+				got = 1
+			} else if score < -1000000/4 {
+				got = -1
+			}
+
+			if got != test.want {
+				t.Errorf("score %d, want %d", score, test.want)
+			}
+		})
+	}
+
+	// the sooner the win, the higher the score
+	early, _ := board.ParsePosition(blocked + " 0")
+	late := early
+	late.Turn += 10
+	if GetScore(&early, 1, 0) <= GetScore(&late, 1, 0) {
+		t.Errorf("a late win scores at least as much as an early one")
+	}
+}
+
+/**
+ * Seeds of FuzzPartition, in the notation of FormatPosition like the failing inputs saved by the fuzzer.
+ */
+func addFuzzPositions(f *testing.F) {
+	board.InitAdjacentTilesCache()
+
+	f.Add("........./........./........./........./A.......B/........./........./........./......... 0")
+	f.Add("........./........./........./.#......./.AB....../........./........./........./......... 1")
+	f.Add("A#......./##......./........./........./........./........./........./........./........B 0")
+
+	random := rand.New(rand.NewSource(3))
+	for i := 0; i < 10; i++ {
+		currentState := movegen.RandomPosition(random)
+		f.Add(board.FormatPosition(&currentState))
+	}
+}
+
+func FuzzPartition(f *testing.F) {
+	addFuzzPositions(f)
+
+	f.Fuzz(func(t *testing.T, position string) {
+		currentState, err := board.ParsePosition(position)
+		if err != nil {
+			t.Skip()
+		}
+
+		// the tiles of the pawns are counted in their partition
+		freeTilesCount := board.GridSize - currentState.BoardRemoved.Count()
+
+		myCellsCount, opponentCellsCount := CountPartitionCells(&currentState, 0)
+		if myCellsCount+opponentCellsCount > freeTilesCount {
+			t.Fatalf("%d + %d cells in the partitions for %d free tiles in %s", myCellsCount, opponentCellsCount, freeTilesCount, position)
+		}
+
+		if mySwappedCellsCount, opponentSwappedCellsCount := CountPartitionCells(&currentState, 1); mySwappedCellsCount != opponentCellsCount || opponentSwappedCellsCount != myCellsCount {
+			t.Fatalf("the partition of player 1 is %d %d, want %d %d in %s", mySwappedCellsCount, opponentSwappedCellsCount, opponentCellsCount, myCellsCount, position)
+		}
+
+		swappedState := currentState
+		swappedState.PlayersPosition[0], swappedState.PlayersPosition[1] = currentState.PlayersPosition[1], currentState.PlayersPosition[0]
+		swappedState.PlayerToMove = 1 - currentState.PlayerToMove
+
+		if mySwappedCellsCount, opponentSwappedCellsCount := CountPartitionCells(&swappedState, 0); mySwappedCellsCount != opponentCellsCount || opponentSwappedCellsCount != myCellsCount {
+			t.Fatalf("the partition is %d %d with the players swapped, want %d %d in %s", mySwappedCellsCount, opponentSwappedCellsCount, opponentCellsCount, myCellsCount, position)
+		}
+
+		// the symmetric positions share their entry of the transposition table
+		score := GetScore(&currentState, 0, currentState.PlayerToMove)
+		for _, symmetry := range board.GetSymmetries() {
+			transformedState := board.TransformState(&currentState, symmetry)
+			if transformedScore := GetScore(&transformedState, board.TransformPlayer(0, symmetry), transformedState.PlayerToMove); transformedScore != score {
+				t.Fatalf("the score is %d after the %v, want %d in %s", transformedScore, symmetry, score, position)
+			}
+		}
+	})
+}
+
+/**
+ * Tells if the pawn of player 0 can reach the pawn of player 1 through free tiles, by a breadth first search.
+ */
+func arePawnsConnected(currentState *board.State) bool {
+	visited := board.CompactBoolArray{}
+	queue := []board.Coord{currentState.PlayersPosition[0]}
+	visited.Set(board.TileIndex(queue[0]), true)
+
+	for len(queue) > 0 {
+		position := queue[0]
+		queue = queue[1:]
+
+		for _, adj := range *board.GetAdjacentTiles(position) {
+			if adj == currentState.PlayersPosition[1] {
+				return true
+			}
+			if !visited.Get(board.TileIndex(adj)) && board.IsTileFree(currentState, &adj) {
+				visited.Set(board.TileIndex(adj), true)
+				queue = append(queue, adj)
+			}
+		}
+	}
+
+	return false
+}
+
+func FuzzSeparatingTiles(f *testing.F) {
+	addFuzzPositions(f)
+	f.Add("........./........./........./####.####/A.......B/####.####/........./........./......... 0")
+
+	f.Fuzz(func(t *testing.T, position string) {
+		currentState, err := board.ParsePosition(position)
+		if err != nil || board.PlayersCount != 2 {
+			t.Skip()
+		}
+
+		separatingTiles := GetSeparatingTiles(&currentState, 0, 1)
+		connected := arePawnsConnected(&currentState)
+
+		for _, tile := range board.GetTiles() {
+			if !board.IsTileFree(&currentState, &tile) {
+				if separatingTiles.Get(board.TileIndex(tile)) {
+					t.Fatalf("%v separates the pawns but isn't free in %s", tile, position)
+				}
+				continue
+			}
+
+			removedState := currentState
+			removedState.BoardRemoved.Set(board.TileIndex(tile), true)
+			if separates := connected && !arePawnsConnected(&removedState); separatingTiles.Get(board.TileIndex(tile)) != separates {
+				t.Fatalf("removing %v separates the pawns: %v, want %v in %s", tile, separatingTiles.Get(board.TileIndex(tile)), separates, position)
+			}
+		}
+	})
+}
+
+func TestPartitionOfSeveralPlayers(t *testing.T) {
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+
+	if err := board.SetConfig(board.NewConfigWithPlayers(7, 7, 4)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the pawns in the middle of the sides own the same number of tiles, the diagonals are contested
+	initial := board.InitialState()
+	colorGrid := [board.MAX_HEIGHT][board.MAX_WIDTH]int8{}
+	if cellsCounts := ComputePartition(&initial, &colorGrid); cellsCounts != [board.MAX_PLAYERS]int{9, 9, 9, 9} {
+		t.Fatalf("cells counts of the initial position %v, want 9 each:\n%s", cellsCounts, showColorGrid(colorGrid))
+	}
+	if myCellsCount, opponentCellsCount := CountPartitionCells(&initial, 2); myCellsCount != 9 || opponentCellsCount != 27 {
+		t.Fatalf("partition of player 2 is %d %d, want 9 27", myCellsCount, opponentCellsCount)
+	}
+
+	// an eliminated player owns no tile, not even the one of its pawn
+	eliminated := initial
+	eliminated.PlayerToMove = 3
+	eliminated = board.EliminatePlayer(&eliminated)
+	if cellsCounts := ComputePartition(&eliminated, &[board.MAX_HEIGHT][board.MAX_WIDTH]int8{}); cellsCounts[3] != 0 || cellsCounts[0]+cellsCounts[1]+cellsCounts[2] > board.GridSize-1 {
+		t.Fatalf("cells counts with player 3 eliminated %v", cellsCounts)
+	}
+
+	random := rand.New(rand.NewSource(5))
+	for i := 0; i < 100; i++ {
+		currentState := movegen.RandomPosition(random)
+		cellsCounts := ComputePartition(&currentState, &[board.MAX_HEIGHT][board.MAX_WIDTH]int8{})
+
+		total := 0
+		for _, cellsCount := range cellsCounts {
+			total += cellsCount
+		}
+		if total > board.GridSize-currentState.BoardRemoved.Count() {
+			t.Fatalf("%v cells in the partitions for %d free tiles in %s", cellsCounts, board.GridSize-currentState.BoardRemoved.Count(), board.FormatPosition(&currentState))
+		}
+
+		// the cells counts follow the pawns
+		swappedState := currentState
+		swappedState.PlayersPosition[0], swappedState.PlayersPosition[2] = currentState.PlayersPosition[2], currentState.PlayersPosition[0]
+		swappedState.EliminatedPlayers = 0
+		currentState.EliminatedPlayers = 0
+		cellsCounts = ComputePartition(&currentState, &[board.MAX_HEIGHT][board.MAX_WIDTH]int8{})
+		swappedCellsCounts := ComputePartition(&swappedState, &[board.MAX_HEIGHT][board.MAX_WIDTH]int8{})
+		if swappedCellsCounts[0] != cellsCounts[2] || swappedCellsCounts[2] != cellsCounts[0] || swappedCellsCounts[1] != cellsCounts[1] {
+			t.Fatalf("cells counts %v with the pawns of players 0 and 2 swapped, want them swapped in %v in %s", swappedCellsCounts, cellsCounts, board.FormatPosition(&currentState))
+		}
+	}
+}
+
+/**
+ * The plain rendering of a 5x3 board: the pawns, the removed tiles, the territory of each player and the contested tiles,
+ * the tiles of the last action prefixed by * and the territory totals.
+ */
+func TestRenderBoard(t *testing.T) {
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+
+	if err := board.SetConfig(board.NewConfig(5, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	currentState, err := board.ParsePosition(".#.../A.#.B/..... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastAction := board.Action{MovePosition: board.Coord{X: 4, Y: 1}, RemoveTile: board.Coord{X: 2, Y: 1}}
+
+	want := "" +
+		"   0 1 2 3 4\n" +
+		" 0 a # + b b\n" +
+		" 1 A a*# b*B\n" +
+		" 2 a a + b b\n" +
+		"territory: A 5, B 6\n"
+
+	if got := RenderBoard(&currentState, RenderOptions{LastAction: &lastAction, Territory: true}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// without the overlay, the free tiles are .
+	if got := RenderBoard(&currentState, RenderOptions{}); !strings.Contains(got, " 1 A . # . B\n") || strings.Contains(got, "territory") {
+		t.Errorf("got\n%s\nwithout the territory", got)
+	}
+}
@@ -0,0 +1,119 @@
+package eval
+
+import (
+	"fmt"
+	"strings"
+
+	"isola/board"
+)
+
+const ANSI_RESET = "\033[0m"
+const ANSI_REVERSE = "\033[7m"
+const ANSI_PLAYER_0 = "\033[1;31m"
+const ANSI_PLAYER_1 = "\033[1;34m"
+const ANSI_PLAYER_2 = "\033[1;32m"
+const ANSI_PLAYER_3 = "\033[1;35m"
+const ANSI_TERRITORY_0 = "\033[31m"
+const ANSI_TERRITORY_1 = "\033[34m"
+const ANSI_TERRITORY_2 = "\033[32m"
+const ANSI_TERRITORY_3 = "\033[35m"
+const ANSI_TERRITORY_BOTH = "\033[33m"
+const ANSI_REMOVED = "\033[90m"
+
+var playerColors = [board.MAX_PLAYERS]string{ANSI_PLAYER_0, ANSI_PLAYER_1, ANSI_PLAYER_2, ANSI_PLAYER_3}
+var territoryColors = [board.MAX_PLAYERS]string{ANSI_TERRITORY_0, ANSI_TERRITORY_1, ANSI_TERRITORY_2, ANSI_TERRITORY_3}
+
+type RenderOptions struct {
+	// the move and the removed tile of this action are highlighted, nil for none
+	LastAction *board.Action
+	// overlay the tiles owned by each player as computed by ComputePartition
+	Territory bool
+	// use ANSI colors for terminals, plain text otherwise (CodinGame stderr)
+	Colors bool
+}
+
+/**
+ * Renders the board as a grid with coordinates.
+ * Pawns are A (player 0), B (player 1), C and D, removed tiles are # and free tiles are .
+ * The pawns of the eliminated players are grey in color mode.
+ * With the territory overlay, free tiles owned by a player are a, b, c or d, and tiles at equal distance are +.
+ * The last action is prefixed by * in plain mode and shown in reverse video in color mode.
+ */
+func RenderBoard(currentState *board.State, options RenderOptions) string {
+	colorGrid := [board.MAX_HEIGHT][board.MAX_WIDTH]int8{}
+	cellsCounts := [board.MAX_PLAYERS]int{}
+
+	if options.Territory {
+		cellsCounts = ComputePartition(currentState, &colorGrid)
+	}
+
+	var result strings.Builder
+
+	result.WriteString("  ")
+	// the units digit only, so that the columns stay aligned on boards wider than 10 tiles
+	for x := 0; x < int(board.Width); x++ {
+		fmt.Fprintf(&result, " %d", x%10)
+	}
+	result.WriteString("\n")
+
+	for y := uint8(0); y < board.Height; y++ {
+		fmt.Fprintf(&result, "%2d", y)
+
+		for x := uint8(0); x < board.Width; x++ {
+			c := board.Coord{X: x, Y: y}
+			symbol, color := getTileSymbol(currentState, &c, colorGrid[y][x])
+			highlighted := options.LastAction != nil && (options.LastAction.MovePosition == c || board.Contains(board.GetRemovedTiles(options.LastAction), c))
+
+			if options.Colors {
+				result.WriteString(" ")
+				if highlighted {
+					result.WriteString(ANSI_REVERSE)
+				}
+				result.WriteString(color + symbol + ANSI_RESET)
+			} else if highlighted {
+				result.WriteString("*" + symbol)
+			} else {
+				result.WriteString(" " + symbol)
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	if options.Territory {
+		result.WriteString("territory:")
+		for playerId := 0; playerId < int(board.PlayersCount); playerId++ {
+			if playerId > 0 {
+				result.WriteString(",")
+			}
+			fmt.Fprintf(&result, " %c %d", 'A'+playerId, cellsCounts[playerId])
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+func getTileSymbol(currentState *board.State, position *board.Coord, owner int8) (symbol string, color string) {
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if currentState.PlayersPosition[playerId] != *position {
+			continue
+		}
+		if board.IsEliminated(currentState, playerId) {
+			return string(rune('A' + playerId)), ANSI_REMOVED
+		}
+		return string(rune('A' + playerId)), playerColors[playerId]
+	}
+
+	switch {
+	case board.IsTileRemoved(currentState, position):
+		return "#", ANSI_REMOVED
+	case owner == -1:
+		return "a", ANSI_TERRITORY_0
+	case owner >= 1 && owner < board.MAX_PLAYERS:
+		return string(rune('a' + owner)), territoryColors[owner]
+	case owner == 42:
+		return "+", ANSI_TERRITORY_BOTH
+	default:
+		return ".", ""
+	}
+}
@@ -0,0 +1,211 @@
+package movegen
+
+import (
+	"math/rand"
+
+	"isola/board"
+)
+
+func GetPossibleActions(currentState *board.State, playerId uint8) []board.Action {
+	return GenerateActions(currentState, playerId, make([]board.Action, 0))
+}
+
+/**
+ * Removal mode of the move generator. When false, only the free tiles the next opponent could move to are removed,
+ * or any free tile if that leaves no action. When true, every legal action is generated.
+ */
+var AllowNotNeighbor = false
+
+/**
+ * Appends the actions of playerId under board.GameRules to actions and returns it.
+ * minimax generates them in the stack of its ply so that searching a node doesn't allocate.
+ */
+func GenerateActions(currentState *board.State, playerId uint8, actions []board.Action) []board.Action {
+	return generateActions(currentState, playerId, AllowNotNeighbor, actions)
+}
+
+func generateActions(currentState *board.State, playerId uint8, full bool, actions []board.Action) []board.Action {
+	opponentTiles := *board.GetAdjacentTiles(currentState.PlayersPosition[board.GetNextPlayer(currentState, playerId)])
+
+	if board.GameRules.RemoveFirst {
+		actionsCount := len(actions)
+
+		if !full {
+			actions = appendRemovalsThenMoves(currentState, playerId, opponentTiles, actions)
+		}
+		if len(actions) == actionsCount {
+			actions = appendRemovalsThenMoves(currentState, playerId, board.GetTiles(), actions)
+		}
+
+		return actions
+	}
+
+	myPosition := currentState.PlayersPosition[playerId]
+
+	adjacentTiles := board.GetAdjacentTiles(myPosition)
+
+	for _, adjacentTile := range *adjacentTiles {
+		if board.IsTileFree(currentState, &adjacentTile) {
+			nextState := board.ApplyMove(currentState, adjacentTile, playerId)
+
+			//debugAny(fmt.Sprintf("next state for %v", adjacentTile), nextState)
+
+			a := board.Action{MovePosition: adjacentTile}
+			actionsCount := len(actions)
+
+			if !full {
+				actions = appendRemovals(&nextState, a, opponentTiles, actions)
+			}
+			if len(actions) == actionsCount {
+				actions = appendRemovals(&nextState, a, board.GetTiles(), actions)
+			}
+		}
+	}
+
+	return actions
+}
+
+/**
+ * Appends a with each set of board.GameRules.Removals free tiles of tiles removed, the second removed tile comes after the first one on the board.
+ */
+func appendRemovals(currentState *board.State, a board.Action, tiles []board.Coord, actions []board.Action) []board.Action {
+	for i := range tiles {
+		if !board.IsTileFree(currentState, &tiles[i]) {
+			continue
+		}
+		a.RemoveTile = tiles[i]
+
+		if board.GameRules.Removals == 1 {
+			actions = append(actions, a)
+			continue
+		}
+
+		for j := i + 1; j < len(tiles); j++ {
+			if board.IsTileFree(currentState, &tiles[j]) {
+				a.RemoveTile, a.SecondRemoveTile = tiles[i], tiles[j]
+				if board.TileIndex(tiles[j]) < board.TileIndex(tiles[i]) {
+					a.RemoveTile, a.SecondRemoveTile = tiles[j], tiles[i]
+				}
+				actions = append(actions, a)
+			}
+		}
+	}
+
+	return actions
+}
+
+/**
+ * With board.GameRules.RemoveFirst: appends the actions removing free tiles of tiles, each followed by the moves left to playerId.
+ */
+func appendRemovalsThenMoves(currentState *board.State, playerId uint8, tiles []board.Coord, actions []board.Action) []board.Action {
+	removalsStart := len(actions)
+	actions = appendRemovals(currentState, board.Action{}, tiles, actions)
+	removalsEnd := len(actions)
+
+	// the removals are appended first, then replaced by the actions that move after them
+	for i := removalsStart; i < removalsEnd; i++ {
+		a := actions[i]
+
+		removedState := *currentState
+		for _, tile := range board.GetRemovedTiles(&a) {
+			removedState.BoardRemoved.Set(board.TileIndex(tile), true)
+		}
+
+		for _, adjacentTile := range *board.GetAdjacentTiles(currentState.PlayersPosition[playerId]) {
+			if board.IsTileFree(&removedState, &adjacentTile) {
+				a.MovePosition = adjacentTile
+				actions = append(actions, a)
+			}
+		}
+	}
+
+	return append(actions[:removalsStart], actions[removalsEnd:]...)
+}
+
+/**
+ * The number of tiles playerId can move to, its mobility.
+ */
+func GetPossibleActionsCount(currentState *board.State, playerId uint8) int {
+	count := 0
+
+	myPosition := currentState.PlayersPosition[playerId]
+
+	adjacentTiles := board.GetAdjacentTiles(myPosition)
+
+	for _, adjacentTile := range *adjacentTiles {
+		if board.IsTileFree(currentState, &adjacentTile) {
+			count++
+		}
+	}
+
+	return count
+}
+
+/**
+ * A position reached by playing random legal actions from the initial state, the game may be over.
+ * With more than two players, the players who can't play are eliminated on the way.
+ */
+func RandomPosition(random *rand.Rand) board.State {
+	currentState := board.InitialState()
+
+	for plies := random.Intn(60); plies > 0; plies-- {
+		currentState = EliminateStuckPlayers(&currentState)
+		legalActions := GetLegalActions(&currentState, currentState.PlayerToMove)
+		if len(legalActions) == 0 {
+			break
+		}
+		currentState = board.ApplyAction(&currentState, &legalActions[random.Intn(len(legalActions))])
+	}
+
+	return currentState
+}
+
+/**
+ * Tells if playerId can play a whole turn of board.GameRules: a player who can't loses.
+ */
+func CanPlay(currentState *board.State, playerId uint8) bool {
+	return GetPossibleActionsCount(currentState, playerId) > 0 && HasTilesToRemove(currentState)
+}
+
+/**
+ * With more than two players, eliminates the players to move who can't play until one can or only two players are left.
+ */
+func EliminateStuckPlayers(currentState *board.State) board.State {
+	nextState := *currentState
+	for board.GetActivePlayersCount(&nextState) > 2 && !CanPlay(&nextState, nextState.PlayerToMove) {
+		nextState = board.EliminatePlayer(&nextState)
+	}
+	return nextState
+}
+
+/**
+ * Tells if there are enough free tiles to remove in a turn of a player who can move.
+ * After a move, the tile left by the pawn is free. Removing first, a tile to move to must be kept free.
+ */
+func HasTilesToRemove(currentState *board.State) bool {
+	if board.GameRules.Removals == 1 && !board.GameRules.RemoveFirst {
+		return true
+	}
+
+	// the pawns of the eliminated players stay on their tiles
+	freeTilesCount := board.GridSize - currentState.BoardRemoved.Count() - int(board.PlayersCount)
+
+	if board.GameRules.RemoveFirst {
+		return freeTilesCount > int(board.GameRules.Removals)
+	}
+	return freeTilesCount >= int(board.GameRules.Removals)
+}
+
+/**
+ * Every action allowed by the rules, any free tile can be removed.
+ */
+func GetLegalActions(currentState *board.State, playerId uint8) []board.Action {
+	return GenerateLegalActions(currentState, playerId, make([]board.Action, 0))
+}
+
+/**
+ * Appends every action allowed by the rules to actions and returns it, like GenerateActions with AllowNotNeighbor.
+ */
+func GenerateLegalActions(currentState *board.State, playerId uint8, actions []board.Action) []board.Action {
+	return generateActions(currentState, playerId, true, actions)
+}
@@ -0,0 +1,346 @@
+package movegen
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"isola/board"
+)
+
+func sortActions(actions []board.Action) []board.Action {
+	sort.Slice(actions, func(i, j int) bool {
+		return board.FormatAction(&actions[i]) < board.FormatAction(&actions[j])
+	})
+	return actions
+}
+
+func TestMoveGeneratorMatchesReference(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(full bool) { AllowNotNeighbor = full }(AllowNotNeighbor)
+
+	for _, full := range []bool{false, true} {
+		t.Run(fmt.Sprintf("full removal %v", full), func(t *testing.T) {
+			AllowNotNeighbor = full
+			random := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 50; i++ {
+				currentState := RandomPosition(random)
+
+				for _, playerId := range []uint8{0, 1} {
+					got := sortActions(GetPossibleActions(&currentState, playerId))
+					want := sortActions(GetReferenceActions(&currentState, playerId))
+
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("player %d in %s: generated %d actions, want %d", playerId, board.FormatPosition(&currentState), len(got), len(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPerftMatchesReference(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	random := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 5; i++ {
+		currentState := RandomPosition(random)
+
+		got := PerftDivide(&currentState, 2, false)
+		want := PerftDivide(&currentState, 2, true)
+
+		sort.Slice(got, func(i, j int) bool { return board.FormatAction(&got[i].Action) < board.FormatAction(&got[j].Action) })
+		sort.Slice(want, func(i, j int) bool { return board.FormatAction(&want[i].Action) < board.FormatAction(&want[j].Action) })
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("perft divide of %s:\n%v\nwant\n%v", board.FormatPosition(&currentState), got, want)
+		}
+	}
+
+	initial := board.InitialState()
+	if nodes := Perft(&initial, 2, 0); nodes != 680 {
+		t.Errorf("perft 2 of the initial position = %d, want 680", nodes)
+	}
+}
+
+func TestMoveGeneratorOnOtherBoards(t *testing.T) {
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+	defer func(full bool) { AllowNotNeighbor = full }(AllowNotNeighbor)
+
+	for _, notation := range []string{"5x5", "4x6 1,0 2,5", "12x11", "16x16 0,0 15,15", "7x7 players=3", "6x6 players=4"} {
+		t.Run(notation, func(t *testing.T) {
+			config, err := board.ParseConfig(notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := board.SetConfig(config); err != nil {
+				t.Fatal(err)
+			}
+
+			random := rand.New(rand.NewSource(4))
+
+			for i := 0; i < 10; i++ {
+				currentState := RandomPosition(random)
+				if !CanPlay(&currentState, currentState.PlayerToMove) && board.GetActivePlayersCount(&currentState) > 2 {
+					t.Fatalf("player %d can't play and is not eliminated in %s", currentState.PlayerToMove, board.FormatPosition(&currentState))
+				}
+
+				for _, full := range []bool{false, true} {
+					AllowNotNeighbor = full
+
+					got := sortActions(GetPossibleActions(&currentState, currentState.PlayerToMove))
+					want := sortActions(GetReferenceActions(&currentState, currentState.PlayerToMove))
+
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("full removal %v in %s: generated %d actions, want %d", full, board.FormatPosition(&currentState), len(got), len(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRulesOfActions(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(full bool) { AllowNotNeighbor = full }(AllowNotNeighbor)
+
+	initial := "........./........./........./........./A.......B/........./........./........./......... 0"
+	// the pawns are next to each other, with a removed tile above A
+	contact := "........./........./........./.#......./.AB....../........./........./........./......... 0"
+
+	tests := []struct {
+		name     string
+		position string
+		playerId uint8
+		action   board.Action
+		// part of the error message, empty for a legal action
+		wantErr string
+	}{
+		{"move and remove", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}, ""},
+		{"move diagonally", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 3}, RemoveTile: board.Coord{X: 3, Y: 3}}, ""},
+		{"move for player 1", initial, 1, board.Action{MovePosition: board.Coord{X: 7, Y: 5}, RemoveTile: board.Coord{X: 3, Y: 3}}, ""},
+		{"remove the tile left by the pawn", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 0, Y: 4}}, ""},
+		{"stay put", initial, 0, board.Action{MovePosition: board.Coord{X: 0, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}, "stay put"},
+		{"move too far", initial, 0, board.Action{MovePosition: board.Coord{X: 2, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}, "not adjacent"},
+		{"move outside of the board", initial, 1, board.Action{MovePosition: board.Coord{X: 9, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}, "outside of the board"},
+		{"move onto the opponent", contact, 0, board.Action{MovePosition: board.Coord{X: 2, Y: 4}, RemoveTile: board.Coord{X: 3, Y: 3}}, "occupied by the opponent"},
+		{"move onto a removed tile", contact, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 3}, RemoveTile: board.Coord{X: 3, Y: 3}}, "the tile is removed"},
+		{"remove the tile of the opponent", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 8, Y: 4}}, "occupied by a pawn"},
+		{"remove the tile of the pawn", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 1, Y: 4}}, "occupied by a pawn"},
+		{"remove a removed tile", contact, 0, board.Action{MovePosition: board.Coord{X: 0, Y: 4}, RemoveTile: board.Coord{X: 1, Y: 3}}, "already removed"},
+		{"remove outside of the board", initial, 0, board.Action{MovePosition: board.Coord{X: 1, Y: 4}, RemoveTile: board.Coord{X: 4, Y: 9}}, "outside of the board"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currentState, err := board.ParsePosition(test.position)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = board.ValidateAction(&currentState, &test.action, test.playerId)
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("%v is illegal: %v", test.action, err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("error = %v, want %q", err, test.wantErr)
+			}
+
+			for _, full := range []bool{false, true} {
+				AllowNotNeighbor = full
+				if board.GetActionIndex(GetPossibleActions(&currentState, test.playerId), test.action) != -1 {
+					t.Errorf("the illegal action %v is generated with full removal %v", test.action, full)
+				}
+			}
+		})
+	}
+}
+
+/**
+ * Sets the rules of notation for the rest of the test, the rules of CodinGame are restored at its end.
+ */
+func setRules(t *testing.T, notation string) {
+	rules, err := board.ParseRules(notation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := board.SetRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { board.SetRules(board.DEFAULT_RULES) })
+}
+
+func TestMoveGeneratorUnderEachRules(t *testing.T) {
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+	defer func(full bool) { AllowNotNeighbor = full }(AllowNotNeighbor)
+
+	if err := board.SetConfig(board.NewConfig(5, 5)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, notation := range []string{"orthogonal", "knight", "king remove-first", "king removals=2", "orthogonal remove-first removals=2", "knight blocked=2,2 blocked=1,3"} {
+		t.Run(notation, func(t *testing.T) {
+			setRules(t, notation)
+			random := rand.New(rand.NewSource(5))
+
+			for i := 0; i < 20; i++ {
+				currentState := RandomPosition(random)
+
+				for _, full := range []bool{false, true} {
+					AllowNotNeighbor = full
+
+					got := sortActions(GetPossibleActions(&currentState, currentState.PlayerToMove))
+					want := sortActions(GetReferenceActions(&currentState, currentState.PlayerToMove))
+
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("full removal %v in %s: generated %d actions, want %d", full, board.FormatPosition(&currentState), len(got), len(want))
+					}
+
+					if CanPlay(&currentState, currentState.PlayerToMove) != (len(got) > 0) {
+						t.Fatalf("CanPlay is %v with %d actions in %s", !(len(got) > 0), len(got), board.FormatPosition(&currentState))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRulesOfVariants(t *testing.T) {
+	initial := "........./........./........./........./A.......B/........./........./........./......... 0"
+
+	tests := []struct {
+		name   string
+		rules  string
+		action string
+		// part of the error message, empty for a legal action
+		wantErr string
+	}{
+		{"orthogonal move", "orthogonal", "1 4 3 3", ""},
+		{"diagonal move with orthogonal moves", "orthogonal", "1 3 3 3", "not adjacent"},
+		{"knight move", "knight", "2 5 3 3", ""},
+		{"king move with knight moves", "knight", "1 4 3 3", "not adjacent"},
+		{"remove the tile left by the pawn after moving", "king", "1 4 0 4", ""},
+		{"remove the tile of the pawn before moving", "king remove-first", "1 4 0 4", "occupied by a pawn"},
+		{"remove the tile to move to before moving", "king remove-first", "1 4 1 4", "the tile is removed"},
+		{"remove then move", "king remove-first", "1 4 3 3", ""},
+		{"remove two tiles", "king removals=2", "1 4 3 3 7 4", ""},
+		{"remove the same tile twice", "king removals=2", "1 4 3 3 3 3", "already removed"},
+		{"remove the tile to move to with the second removal", "king remove-first removals=2", "1 4 3 3 1 4", "the tile is removed"},
+		{"move to a blocked tile", "king blocked=1,4", "1 4 3 3", "the tile is removed"},
+		{"remove a blocked tile", "king blocked=3,3", "1 4 3 3", "already removed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setRules(t, test.rules)
+
+			currentState, err := board.ParsePosition(initial)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, tile := range board.GameRules.BlockedTiles {
+				currentState.BoardRemoved.Set(board.TileIndex(tile), true)
+			}
+
+			a, err := board.ParseAction(test.action)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = board.ValidateAction(&currentState, &a, 0)
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("%s is illegal: %v", test.action, err)
+				}
+				if board.GetActionIndex(GetLegalActions(&currentState, 0), a) == -1 {
+					t.Fatalf("the legal action %s is not generated", test.action)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("error = %v, want %q", err, test.wantErr)
+			}
+			if board.GetActionIndex(GetLegalActions(&currentState, 0), a) != -1 {
+				t.Fatalf("the illegal action %s is generated", test.action)
+			}
+		})
+	}
+}
+
+/**
+ * Seeds of the fuzz targets: positions in the notation of board.FormatPosition, so that a failing input found by the fuzzer
+ * and saved in testdata/fuzz can be replayed with the other commands.
+ */
+func addFuzzPositions(f *testing.F, add func(position string)) {
+	board.InitAdjacentTilesCache()
+
+	add("........./........./........./........./A.......B/........./........./........./......... 0")
+	add("........./........./........./.#......./.AB....../........./........./........./......... 1")
+	add("A#......./##......./........./........./........./........./........./........./........B 0")
+
+	random := rand.New(rand.NewSource(3))
+	for i := 0; i < 10; i++ {
+		currentState := RandomPosition(random)
+		add(board.FormatPosition(&currentState))
+	}
+}
+
+/**
+ * Plays the legal actions chosen by the bytes of choices from a position and checks the state after each of them.
+ */
+func FuzzApplyAction(f *testing.F) {
+	addFuzzPositions(f, func(position string) {
+		f.Add(position, []byte{0, 17, 42, 255})
+	})
+
+	f.Fuzz(func(t *testing.T, position string, choices []byte) {
+		currentState, err := board.ParsePosition(position)
+		if err != nil {
+			t.Skip()
+		}
+
+		for _, choice := range choices {
+			legalActions := GetLegalActions(&currentState, currentState.PlayerToMove)
+			if len(legalActions) == 0 {
+				return
+			}
+
+			a := legalActions[int(choice)%len(legalActions)]
+			nextState := board.ApplyAction(&currentState, &a)
+
+			switch {
+			case nextState.PlayersPosition[0] == a.RemoveTile || nextState.PlayersPosition[1] == a.RemoveTile:
+				t.Fatalf("%s removes the tile of a pawn in %s", board.FormatAction(&a), board.FormatPosition(&currentState))
+			case board.IsTileRemoved(&nextState, &nextState.PlayersPosition[0]) || board.IsTileRemoved(&nextState, &nextState.PlayersPosition[1]):
+				t.Fatalf("%s leaves a pawn on a removed tile in %s", board.FormatAction(&a), board.FormatPosition(&currentState))
+			case nextState.BoardRemoved.Count() != currentState.BoardRemoved.Count()+1:
+				t.Fatalf("%s doesn't remove exactly one tile in %s", board.FormatAction(&a), board.FormatPosition(&currentState))
+			case nextState.PlayerToMove == currentState.PlayerToMove:
+				t.Fatalf("%s doesn't pass the turn in %s", board.FormatAction(&a), board.FormatPosition(&currentState))
+			}
+
+			// an equal state built from the notation has the same hash
+			parsedState, err := board.ParsePosition(board.FormatPosition(&nextState))
+			if err != nil {
+				t.Fatalf("can't parse %s: %v", board.FormatPosition(&nextState), err)
+			}
+			if board.HashState(&parsedState) != board.HashState(&nextState) {
+				t.Fatalf("two equal states have different hashes: %s", board.FormatPosition(&nextState))
+			}
+
+			currentState = nextState
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package movegen
+
+import (
+	"isola/board"
+)
+
+var perftActionsStack board.ActionsStack
+
+/**
+ * The number of leaf nodes at depth from the state.
+ */
+func Perft(currentState *board.State, depth int, ply int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	possibleActions := perftActionsStack.Keep(ply, GenerateActions(currentState, currentState.PlayerToMove, perftActionsStack.Reuse(ply)))
+
+	if depth == 1 {
+		return len(possibleActions)
+	}
+
+	nodes := 0
+	for i := range possibleActions {
+		nextState := board.ApplyAction(currentState, &possibleActions[i])
+		nodes += Perft(&nextState, depth-1, ply+1)
+	}
+
+	return nodes
+}
+
+type PerftResult struct {
+	Action board.Action
+	Nodes  int
+}
+
+func PerftDivide(currentState *board.State, depth int, reference bool) []PerftResult {
+	rootActions := GetPossibleActions(currentState, currentState.PlayerToMove)
+	if reference {
+		rootActions = GetReferenceActions(currentState, currentState.PlayerToMove)
+	}
+
+	results := make([]PerftResult, len(rootActions))
+
+	for i := range rootActions {
+		nextState := board.ApplyAction(currentState, &rootActions[i])
+
+		results[i].Action = rootActions[i]
+		if reference {
+			results[i].Nodes = ReferencePerft(&nextState, depth-1)
+		} else {
+			results[i].Nodes = Perft(&nextState, depth-1, 1)
+		}
+	}
+
+	return results
+}
+
+func ReferencePerft(currentState *board.State, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	nodes := 0
+	for _, a := range GetReferenceActions(currentState, currentState.PlayerToMove) {
+		nextState := board.ApplyAction(currentState, &a)
+		nodes += ReferencePerft(&nextState, depth-1)
+	}
+
+	return nodes
+}
+
+/**
+ * Slow reference of GenerateActions: tries every move and set of removed tiles of the board against board.ValidateAction.
+ * Unless AllowNotNeighbor is set, the actions that only remove tiles the next opponent could move to are kept if there are some:
+ * for each move when the pawn moves first, among all the actions when the tiles are removed first.
+ */
+func GetReferenceActions(currentState *board.State, playerId uint8) []board.Action {
+	actions := make([]board.Action, 0)
+	actionsNextToOpponent := make([]board.Action, 0)
+
+	opponentPosition := currentState.PlayersPosition[board.GetNextPlayer(currentState, playerId)]
+
+	for moveIndex := 0; moveIndex < board.GridSize; moveIndex++ {
+		movePosition := board.Coord{X: uint8(moveIndex % int(board.Width)), Y: uint8(moveIndex / int(board.Width))}
+
+		moveActions := make([]board.Action, 0)
+		moveActionsNextToOpponent := make([]board.Action, 0)
+
+		for _, a := range getReferenceRemovals(movePosition) {
+			a := a
+			if board.ValidateAction(currentState, &a, playerId) != nil {
+				continue
+			}
+
+			moveActions = append(moveActions, a)
+
+			nextToOpponent := true
+			for _, tile := range board.GetRemovedTiles(&a) {
+				nextToOpponent = nextToOpponent && board.IsMove(opponentPosition, tile)
+			}
+			if nextToOpponent {
+				moveActionsNextToOpponent = append(moveActionsNextToOpponent, a)
+			}
+		}
+
+		if !AllowNotNeighbor && !board.GameRules.RemoveFirst && len(moveActionsNextToOpponent) > 0 {
+			moveActions = moveActionsNextToOpponent
+		}
+
+		actions = append(actions, moveActions...)
+		actionsNextToOpponent = append(actionsNextToOpponent, moveActionsNextToOpponent...)
+	}
+
+	if !AllowNotNeighbor && board.GameRules.RemoveFirst && len(actionsNextToOpponent) > 0 {
+		return actionsNextToOpponent
+	}
+
+	return actions
+}
+
+/**
+ * The actions moving to movePosition with every set of removed tiles, legal or not, without duplicates.
+ */
+func getReferenceRemovals(movePosition board.Coord) []board.Action {
+	actions := make([]board.Action, 0)
+
+	for removeIndex := 0; removeIndex < board.GridSize; removeIndex++ {
+		a := board.Action{MovePosition: movePosition, RemoveTile: board.Coord{X: uint8(removeIndex % int(board.Width)), Y: uint8(removeIndex / int(board.Width))}}
+
+		if board.GameRules.Removals == 1 {
+			actions = append(actions, a)
+			continue
+		}
+
+		for secondRemoveIndex := removeIndex + 1; secondRemoveIndex < board.GridSize; secondRemoveIndex++ {
+			a.SecondRemoveTile = board.Coord{X: uint8(secondRemoveIndex % int(board.Width)), Y: uint8(secondRemoveIndex / int(board.Width))}
+			actions = append(actions, a)
+		}
+	}
+
+	return actions
+}
@@ -0,0 +1,378 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"isola/board"
+	"isola/book"
+	"isola/eval"
+	"isola/movegen"
+	"isola/search"
+)
+
+// time limits of CodinGame, measured from the moment the first line of the turn is read
+var firstTurnDuration = 1000 * time.Millisecond
+var turnDuration = 100 * time.Millisecond
+
+// play the action of book.DefaultBook without searching while the position is in the book
+var UseBook = true
+
+// the search of chooseOutput, the tests replace it to make it fail
+var searchBestAction = search.FindBestMove
+
+/**
+ * Player i always starts at board.StartPositions[i], (0, 4) for player 0 and (8, 4) for player 1 on CodinGame.
+ * The player id is the slot of the pawn in State.PlayersPosition.
+ * Another square means that -board or -rules don't match the game of the referee.
+ */
+func getPlayerIdFromStartPosition(position board.Coord) (uint8, error) {
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if position == board.StartPositions[playerId] {
+			return playerId, nil
+		}
+	}
+	return 0, fmt.Errorf("start square (%d, %d) of no player of the board %s", position.X, position.Y, board.FormatConfig(board.GetConfig()))
+}
+
+func PlayCG(in io.Reader, out io.Writer) {
+
+	board.InitAdjacentTilesCache()
+
+	reader := newProtocolReader(in)
+
+	// playerPosition: player's coordinates.
+	playerPosition, _, err := reader.readCoord(false)
+	if err != nil {
+		search.DebugAny("can't read the initialization input", err)
+		return
+	}
+
+	myPlayerId, err := getPlayerIdFromStartPosition(playerPosition)
+	if err != nil {
+		search.DebugAny("can't find my player id", err)
+		return
+	}
+
+	search.DebugAny("my player id", myPlayerId)
+
+	currentState := board.InitialState()
+
+	for firstTurn := true; ; firstTurn = false {
+		inputs, err := reader.readTurnInputs()
+		if err == io.EOF {
+			search.Debug("end of input")
+			return
+		}
+		if err != nil {
+			search.DebugAny("can't read the turn input", err)
+			return
+		}
+
+		budget := turnDuration
+
+		if firstTurn {
+			budget = firstTurnDuration
+		}
+
+		tm := search.NewTimeManager(inputs[0].receivedAt, search.GetSearchLimits(budget))
+
+		search.DebugAny("time budget", budget)
+
+		var opponentAction *board.Action
+
+		// the opponents play in the order of the inputs, from the player after us
+		for i := range inputs {
+			input := &inputs[i]
+			opponentId := (myPlayerId + 1 + uint8(i)) % board.PlayersCount
+
+			var action *board.Action
+
+			// no tile has been removed by an opponent who didn't play since our last turn
+			if input.hasRemovedTile {
+				action = &board.Action{MovePosition: input.opponentPosition, RemoveTile: input.removedTiles[0], SecondRemoveTile: input.removedTiles[1]}
+				opponentAction = action
+			}
+
+			if err := checkOpponentAction(&currentState, action, input, opponentId); err != nil {
+				search.DebugAny("desync", err)
+				search.Debug("state before the opponent action:\n" + eval.RenderBoard(&currentState, eval.RenderOptions{}))
+				currentState = resyncState(&currentState, input, opponentId)
+			} else if action != nil {
+				currentState = board.ApplyAction(&currentState, action)
+			} else if currentState.PlayerToMove == opponentId {
+				search.DebugAny("eliminated player", opponentId)
+				currentState = board.EliminatePlayer(&currentState)
+			}
+		}
+
+		if currentState.PlayerToMove != myPlayerId {
+			search.DebugAny("desync", fmt.Errorf("player %d to move instead of us", currentState.PlayerToMove))
+			currentState.PlayerToMove = myPlayerId
+		}
+
+		search.DebugAny("current state", currentState)
+		search.Debug(eval.RenderBoard(&currentState, eval.RenderOptions{LastAction: opponentAction, Territory: true}))
+
+		output, chosenAction := chooseOutput(&currentState, myPlayerId, tm)
+
+		// after RANDOM, our pawn is somewhere we don't know, the next input will be a desync
+		if chosenAction != nil {
+			currentState = board.ApplyAction(&currentState, chosenAction)
+		}
+
+		// fmt.Fprintln(os.Stderr, "Debug messages...")
+		fmt.Fprintln(out, output) // action: "x y" to action or "x y message" to action and speak
+	}
+}
+
+const RANDOM_OUTPUT = "RANDOM"
+const RESIGN_OUTPUT = "RANDOM;resign"
+
+/**
+ * Returns the output of the turn and the action it plays: the action of the opening book, the best action found by the search, the greedy action chosen
+ * before the search if it found nothing in time or panicked, RANDOM as a last resort if something went wrong before,
+ * or a resignation when there is no legal action.
+ */
+func chooseOutput(currentState *board.State, myPlayerId uint8, tm *search.TimeManager) (output string, chosenAction *board.Action) {
+	var fallbackAction *board.Action
+
+	defer func() {
+		if r := recover(); r != nil {
+			search.DebugAny("can't choose an action", r)
+			if fallbackAction != nil {
+				search.DebugAny("playing the greedy action", fallbackAction)
+				output, chosenAction = board.FormatAction(fallbackAction), fallbackAction
+				return
+			}
+			output, chosenAction = RANDOM_OUTPUT, nil
+		}
+	}()
+
+	fallbackAction = findGreedyAction(currentState, myPlayerId)
+
+	if fallbackAction == nil {
+		search.Debug("no legal action")
+		return RESIGN_OUTPUT, nil
+	}
+
+	if UseBook {
+		if bookAction, ok := book.DefaultBook.Lookup(currentState); ok {
+			search.DebugAny("book action", bookAction)
+			return board.FormatAction(&bookAction), &bookAction
+		}
+	}
+
+	bestAction, bestScore := searchBestAction(currentState, myPlayerId, tm)
+
+	search.DebugAny("best action", bestAction)
+	search.DebugAny("best score", bestScore)
+
+	if bestAction == nil {
+		search.DebugAny("no action found by the search, playing the greedy action", fallbackAction)
+		return board.FormatAction(fallbackAction), fallbackAction
+	}
+
+	return board.FormatAction(bestAction), bestAction
+}
+
+/**
+ * A fast action that maximizes eval.GetScorePossibleAction after playing it, nil if there is no legal action.
+ */
+func findGreedyAction(currentState *board.State, myPlayerId uint8) *board.Action {
+	var bestAction *board.Action
+	bestScore := 0
+
+	possibleActions := movegen.GetPossibleActions(currentState, currentState.PlayerToMove)
+
+	for i := range possibleActions {
+		nextState := board.ApplyAction(currentState, &possibleActions[i])
+		score := eval.GetScorePossibleAction(&nextState, myPlayerId)
+
+		if bestAction == nil || score > bestScore {
+			bestAction = &possibleActions[i]
+			bestScore = score
+		}
+	}
+
+	return bestAction
+}
+
+/**
+ * Reads the CodinGame input line by line, each line holding one or more integers.
+ */
+type protocolReader struct {
+	scanner *bufio.Scanner
+	fields  []string
+	line    int
+}
+
+/**
+ * The input of a turn about one opponent, there is one for each other player in the order of the turns starting after us.
+ */
+type turnInput struct {
+	opponentPosition board.Coord
+	// board.GameRules.Removals tiles, one on CodinGame
+	removedTiles [board.MAX_REMOVALS]board.Coord
+	// false when the opponent didn't play since our last turn, on the first turn of the game or once it is eliminated,
+	// then the input is -1 -1 for each removed tile
+	hasRemovedTile bool
+	// when the first line of the turn was read, the response time starts there
+	receivedAt time.Time
+}
+
+func newProtocolReader(in io.Reader) *protocolReader {
+	return &protocolReader{scanner: bufio.NewScanner(in)}
+}
+
+/**
+ * Returns io.EOF when the input ends between two values.
+ */
+func (r *protocolReader) readInt() (int, error) {
+	for len(r.fields) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		r.line++
+		r.fields = strings.Fields(r.scanner.Text())
+	}
+
+	field := r.fields[0]
+	r.fields = r.fields[1:]
+
+	value, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: invalid integer %q", r.line, field)
+	}
+
+	return value, nil
+}
+
+/**
+ * Reads x and y, which must be on the board, or -1 -1 if allowNone.
+ */
+func (r *protocolReader) readCoord(allowNone bool) (c board.Coord, none bool, err error) {
+	x, err := r.readInt()
+	if err != nil {
+		return board.Coord{}, false, err
+	}
+
+	y, err := r.readInt()
+	if err == io.EOF {
+		return board.Coord{}, false, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return board.Coord{}, false, err
+	}
+
+	if allowNone && x == -1 && y == -1 {
+		return board.Coord{}, true, nil
+	}
+
+	if x < 0 || x >= int(board.Width) || y < 0 || y >= int(board.Height) {
+		return board.Coord{}, false, fmt.Errorf("line %d: coordinates (%d, %d) outside of the board", r.line, x, y)
+	}
+
+	return board.Coord{X: uint8(x), Y: uint8(y)}, false, nil
+}
+
+func (r *protocolReader) readTurn() (input turnInput, err error) {
+	// opponentPosition: opponent's coordinates.
+	input.opponentPosition, _, err = r.readCoord(false)
+	if err != nil {
+		return input, err
+	}
+
+	input.receivedAt = time.Now()
+
+	// removedTile: coordinates of the last removed tile. (-1 -1) if no tile has been removed.
+	for i := 0; i < int(board.GameRules.Removals); i++ {
+		removedTile, none, err := r.readCoord(true)
+		if err == io.EOF {
+			return input, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return input, err
+		}
+
+		input.removedTiles[i] = removedTile
+		input.hasRemovedTile = !none
+	}
+
+	return input, nil
+}
+
+/**
+ * Reads the input of a turn, one turnInput for each opponent.
+ */
+func (r *protocolReader) readTurnInputs() ([]turnInput, error) {
+	inputs := make([]turnInput, board.PlayersCount-1)
+
+	for i := range inputs {
+		input, err := r.readTurn()
+		if err == io.EOF && i > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		inputs[i] = input
+	}
+
+	return inputs, nil
+}
+
+/**
+ * Checks that the input of the turn about opponentId is consistent with our state: its action is legal,
+ * or there is no action because it hasn't played yet in the game, is eliminated or can't play on its turn.
+ */
+func checkOpponentAction(currentState *board.State, opponentAction *board.Action, input *turnInput, opponentId uint8) error {
+	if opponentAction == nil {
+		if currentState.PlayerToMove == opponentId && movegen.CanPlay(currentState, opponentId) {
+			return fmt.Errorf("no removed tile at turn %d for player %d", currentState.Turn, opponentId)
+		}
+		if input.opponentPosition != currentState.PlayersPosition[opponentId] {
+			return fmt.Errorf("the opponent is at %v instead of %v", input.opponentPosition, currentState.PlayersPosition[opponentId])
+		}
+		return nil
+	}
+
+	if currentState.PlayerToMove != opponentId {
+		return fmt.Errorf("it is not the turn of the opponent")
+	}
+
+	return board.ValidateAction(currentState, opponentAction, opponentId)
+}
+
+/**
+ * Rebuilds the state from the input when it doesn't match our model rather than crashing:
+ * the opponent's pawn is where the input says, the tile it removed is removed and it is the turn of the next player.
+ * The tiles removed without us knowing can't be recovered.
+ */
+func resyncState(currentState *board.State, input *turnInput, opponentId uint8) board.State {
+	nextState := *currentState
+
+	opponentPosition := input.opponentPosition
+	nextState.PlayersPosition[opponentId] = opponentPosition
+	nextState.BoardRemoved.Set(board.TileIndex(opponentPosition), false)
+
+	for i := 0; input.hasRemovedTile && i < int(board.GameRules.Removals); i++ {
+		if !board.IsTileOccupied(&nextState, &input.removedTiles[i]) {
+			nextState.BoardRemoved.Set(board.TileIndex(input.removedTiles[i]), true)
+		}
+	}
+
+	if nextState.PlayerToMove == opponentId {
+		nextState.Turn++
+	}
+	nextState.PlayerToMove = board.GetNextPlayer(&nextState, opponentId)
+
+	return nextState
+}
@@ -0,0 +1,371 @@
+package protocol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+	"isola/search"
+)
+
+/**
+ * Plays a whole game through the referee against a random opponent, the engine talking the CodinGame protocol, on several boards and variants.
+ */
+func TestProtocolPlayingAsEachPlayer(t *testing.T) {
+	defer func(first time.Duration, turn time.Duration) {
+		firstTurnDuration, turnDuration = first, turn
+	}(firstTurnDuration, turnDuration)
+
+	firstTurnDuration = 100 * time.Millisecond
+	turnDuration = 25 * time.Millisecond
+
+	defer board.SetConfig(board.DEFAULT_CONFIG)
+	defer board.SetRules(board.DEFAULT_RULES)
+
+	variants := []struct {
+		config board.Config
+		rules  board.Rules
+	}{
+		{board.DEFAULT_CONFIG, board.DEFAULT_RULES},
+		{board.NewConfig(7, 7), board.DEFAULT_RULES},
+		{board.Config{Width: 12, Height: 10, PlayersCount: 2, StartPositions: [board.MAX_PLAYERS]board.Coord{{X: 2, Y: 3}, {X: 9, Y: 6}}}, board.DEFAULT_RULES},
+		{board.NewConfig(7, 7), board.Rules{Moves: board.KNIGHT_MOVES, Removals: 2}},
+		{board.NewConfig(7, 7), board.Rules{Moves: board.ORTHOGONAL_MOVES, RemoveFirst: true, Removals: 1, BlockedTiles: []board.Coord{{X: 3, Y: 3}}}},
+		{board.NewConfigWithPlayers(7, 7, 3), board.DEFAULT_RULES},
+		{board.NewConfigWithPlayers(9, 9, 4), board.DEFAULT_RULES},
+	}
+
+	for _, variant := range variants {
+		board.SetRules(board.DEFAULT_RULES)
+		if err := board.SetConfig(variant.config); err != nil {
+			t.Fatal(err)
+		}
+		if err := board.SetRules(variant.rules); err != nil {
+			t.Fatal(err)
+		}
+
+		for myPlayerId := uint8(0); myPlayerId < variant.config.PlayersCount; myPlayerId++ {
+			t.Run(fmt.Sprintf("%s %s player %d", board.FormatConfig(variant.config), board.FormatRules(variant.rules), myPlayerId), func(t *testing.T) {
+				r := NewReferee(int64(myPlayerId))
+
+				input, outputScanner, done := startEngine()
+
+				io.WriteString(input, r.GetInitialInput(myPlayerId))
+
+				for !r.IsOver() {
+					if r.State.PlayerToMove != myPlayerId {
+						legalActions := movegen.GetLegalActions(&r.State, r.State.PlayerToMove)
+						opponentAction := legalActions[r.Random.Intn(len(legalActions))]
+
+						if err := r.Play(board.FormatAction(&opponentAction)); err != nil {
+							t.Fatal(err)
+						}
+						continue
+					}
+
+					io.WriteString(input, r.GetTurnInput())
+
+					if !outputScanner.Scan() {
+						t.Fatal("no output from the engine")
+					}
+
+					if err := r.Play(outputScanner.Text()); err != nil {
+						t.Fatalf("turn %d: %v", r.State.Turn, err)
+					}
+				}
+
+				if r.GetWinner() != myPlayerId {
+					t.Errorf("the engine lost against a random opponent at turn %d:\n%s", r.State.Turn, eval.RenderBoard(&r.State, eval.RenderOptions{}))
+				}
+
+				input.Close()
+				waitForEngineExit(t, done)
+			})
+		}
+	}
+}
+
+/**
+ * Runs the CodinGame loop of the engine on pipes, done is closed when it returns.
+ */
+func startEngine() (input *io.PipeWriter, outputScanner *bufio.Scanner, done chan struct{}) {
+	inputReader, input := io.Pipe()
+	outputReader, output := io.Pipe()
+	done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		PlayCG(inputReader, output)
+	}()
+
+	return input, bufio.NewScanner(outputReader), done
+}
+
+func waitForEngineExit(t *testing.T, done chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the engine didn't exit at the end of the input")
+	}
+}
+
+/**
+ * The player id is found from the start square, and the engine stops on a square of no player rather than playing as the wrong one.
+ */
+func TestPlayerIdFromStartPosition(t *testing.T) {
+	for playerId := uint8(0); playerId < board.PlayersCount; playerId++ {
+		if got, err := getPlayerIdFromStartPosition(board.StartPositions[playerId]); err != nil || got != playerId {
+			t.Errorf("got player %d and %v for the start square of player %d", got, err, playerId)
+		}
+	}
+
+	if got, err := getPlayerIdFromStartPosition(board.Coord{X: 4, Y: 4}); err == nil {
+		t.Errorf("got player %d for the middle of the board", got)
+	}
+
+	input, _, done := startEngine()
+	io.WriteString(input, "4 4\n")
+	waitForEngineExit(t, done)
+	input.Close()
+}
+
+func TestProtocolReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    turnInput
+		wantErr bool
+	}{
+		{"one value per line", "8\n4\n-1\n-1\n", turnInput{opponentPosition: board.Coord{X: 8, Y: 4}}, false},
+		{"values on one line", "7 3 2 4\n", turnInput{opponentPosition: board.Coord{X: 7, Y: 3}, removedTiles: [board.MAX_REMOVALS]board.Coord{{X: 2, Y: 4}}, hasRemovedTile: true}, false},
+		{"empty lines", "\n7\n\n3\n2\n4\n", turnInput{opponentPosition: board.Coord{X: 7, Y: 3}, removedTiles: [board.MAX_REMOVALS]board.Coord{{X: 2, Y: 4}}, hasRemovedTile: true}, false},
+		{"not an integer", "7\nthree\n2\n4\n", turnInput{}, true},
+		{"position outside of the board", "9\n3\n2\n4\n", turnInput{}, true},
+		{"no opponent position", "-1\n-1\n2\n4\n", turnInput{}, true},
+		{"removed tile outside of the board", "7\n3\n2\n-4\n", turnInput{}, true},
+		{"truncated", "7\n3\n2\n", turnInput{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := newProtocolReader(strings.NewReader(test.input)).readTurn()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("error = %v, want error %v", err, test.wantErr)
+			}
+
+			got.receivedAt = time.Time{}
+			if !test.wantErr && got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := newProtocolReader(strings.NewReader("")).readTurn(); err != io.EOF {
+		t.Errorf("error at the end of the input = %v, want io.EOF", err)
+	}
+}
+
+/**
+ * After a desync, the engine plays from the state rebuilt from the input: the next action of the opponent is only legal
+ * from the position of the input, and the engine logs no other desync.
+ */
+func TestProtocolDesync(t *testing.T) {
+	defer func(first time.Duration, turn time.Duration) {
+		firstTurnDuration, turnDuration = first, turn
+	}(firstTurnDuration, turnDuration)
+
+	firstTurnDuration = 25 * time.Millisecond
+	turnDuration = 25 * time.Millisecond
+
+	// the log of the engine, where the desyncs are reported
+	logReader, logWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(stderr *os.File) { os.Stderr = stderr }(os.Stderr)
+	os.Stderr = logWriter
+
+	logLines := make(chan []string)
+	go func() {
+		lines := make([]string, 0)
+		for scanner := bufio.NewScanner(logReader); scanner.Scan(); {
+			lines = append(lines, scanner.Text())
+		}
+		logLines <- lines
+	}()
+
+	input, outputScanner, done := startEngine()
+
+	io.WriteString(input, "0\n4\n8\n4\n-1\n-1\n")
+	if !outputScanner.Scan() {
+		t.Fatal("no output from the engine")
+	}
+
+	myAction, err := board.ParseAction(outputScanner.Text())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedState := board.InitialState()
+	expectedState = board.ApplyAction(&expectedState, &myAction)
+
+	// the opponent jumps to a tile that is not adjacent to its pawn
+	io.WriteString(input, "5\n1\n3\n3\n")
+	expectedState = resyncState(&expectedState, &turnInput{opponentPosition: board.Coord{X: 5, Y: 1}, removedTiles: [board.MAX_REMOVALS]board.Coord{{X: 3, Y: 3}}, hasRemovedTile: true}, 1)
+
+	for turn := 0; turn < 2; turn++ {
+		if !outputScanner.Scan() {
+			t.Fatalf("no output from the engine %d turns after the desync", turn)
+		}
+
+		a, err := board.ParseAction(outputScanner.Text())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := board.ValidateAction(&expectedState, &a, 0); err != nil {
+			t.Fatalf("illegal action %d turns after the desync: %v", turn, err)
+		}
+		if turn == 1 {
+			break
+		}
+		expectedState = board.ApplyAction(&expectedState, &a)
+
+		// the opponent moves from (5, 1), where the engine only puts it if it rebuilt its state
+		opponentAction := movegen.GetLegalActions(&expectedState, 1)[0]
+		expectedState = board.ApplyAction(&expectedState, &opponentAction)
+		fmt.Fprintf(input, "%d\n%d\n%d\n%d\n", opponentAction.MovePosition.X, opponentAction.MovePosition.Y, opponentAction.RemoveTile.X, opponentAction.RemoveTile.Y)
+	}
+
+	input.Close()
+	waitForEngineExit(t, done)
+
+	logWriter.Close()
+	desyncs := 0
+	for _, line := range <-logLines {
+		if strings.HasPrefix(line, "desync:") {
+			desyncs++
+		}
+	}
+	if desyncs != 1 {
+		t.Errorf("%d desyncs in the log, want 1", desyncs)
+	}
+}
+
+/**
+ * The initial position is in the opening book, which is turned off to reach the greedy action.
+ */
+func TestChooseOutputWithImmediateDeadline(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(useBook bool) { UseBook = useBook }(UseBook)
+	UseBook = false
+
+	currentState := board.InitialState()
+
+	if bestAction, _ := search.FindBestMove(&currentState, 0, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: time.Nanosecond})); bestAction != nil {
+		t.Fatalf("the search found %v after the deadline", bestAction)
+	}
+
+	output, chosenAction := chooseOutput(&currentState, 0, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: time.Nanosecond}))
+	if chosenAction == nil {
+		t.Fatalf("no fallback action, output %q", output)
+	}
+
+	a, err := board.ParseAction(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a != *chosenAction {
+		t.Errorf("output %q doesn't match the chosen action %v", output, chosenAction)
+	}
+
+	if err := board.ValidateAction(&currentState, &a, 0); err != nil {
+		t.Errorf("illegal fallback action: %v", err)
+	}
+
+	if greedyAction := findGreedyAction(&currentState, 0); greedyAction == nil || a != *greedyAction {
+		t.Errorf("fallback action %v, want the greedy action %v", a, greedyAction)
+	}
+}
+
+/**
+ * A panic of the search plays the greedy action found before it rather than RANDOM.
+ */
+func TestChooseOutputWhenSearchPanics(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(useBook bool) { UseBook = useBook }(UseBook)
+	UseBook = false
+	defer func() { searchBestAction = search.FindBestMove }()
+	searchBestAction = func(currentState *board.State, myPlayerId uint8, tm *search.TimeManager) (*board.Action, int) {
+		panic("search failed")
+	}
+
+	currentState := board.InitialState()
+
+	output, chosenAction := chooseOutput(&currentState, 0, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: time.Second}))
+
+	greedyAction := findGreedyAction(&currentState, 0)
+	if greedyAction == nil || chosenAction == nil || *chosenAction != *greedyAction || output != board.FormatAction(greedyAction) {
+		t.Errorf("got %q %v, want the greedy action %v", output, chosenAction, greedyAction)
+	}
+}
+
+func TestChooseOutputWithoutLegalAction(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	currentState, err := board.ParsePosition("A#......./##......./........./........./........B/........./........./........./......... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, chosenAction := chooseOutput(&currentState, 0, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: time.Second}))
+
+	if output != RESIGN_OUTPUT || chosenAction != nil {
+		t.Errorf("got %q %v, want %q", output, chosenAction, RESIGN_OUTPUT)
+	}
+}
+
+func TestProtocolWithImmediateDeadline(t *testing.T) {
+	defer func(first time.Duration, turn time.Duration) {
+		firstTurnDuration, turnDuration = first, turn
+	}(firstTurnDuration, turnDuration)
+
+	firstTurnDuration = time.Nanosecond
+	turnDuration = time.Nanosecond
+
+	// the first turn is in the opening book
+	defer func(useBook bool) { UseBook = useBook }(UseBook)
+	UseBook = false
+
+	r := NewReferee(0)
+
+	input, outputScanner, done := startEngine()
+
+	io.WriteString(input, r.GetInitialInput(0))
+
+	for i := 0; i < 5 && !r.IsOver(); i++ {
+		io.WriteString(input, r.GetTurnInput())
+
+		if !outputScanner.Scan() {
+			t.Fatal("no output from the engine")
+		}
+
+		if err := r.Play(outputScanner.Text()); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.Play(RANDOM_OUTPUT); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	input.Close()
+	waitForEngineExit(t, done)
+}
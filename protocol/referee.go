@@ -0,0 +1,110 @@
+package protocol
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"isola/board"
+	"isola/movegen"
+)
+
+/**
+ * Referee of a game following the CodinGame protocol: it keeps the real state of the game,
+ * writes the input of each player and checks their outputs.
+ * With more than two players, the player to move who can't play is eliminated until two players are left.
+ */
+type Referee struct {
+	State      board.State
+	LastAction *board.Action
+	// the last action of each player, nil before its first action and once it is eliminated
+	LastActions [board.MAX_PLAYERS]*board.Action
+	Random      *rand.Rand
+}
+
+func NewReferee(seed int64) *Referee {
+	return &Referee{State: board.InitialState(), Random: rand.New(rand.NewSource(seed))}
+}
+
+/**
+ * The initialization input of a player: the coordinates of its pawn.
+ */
+func (r *Referee) GetInitialInput(playerId uint8) string {
+	position := r.State.PlayersPosition[playerId]
+	return fmt.Sprintf("%d\n%d\n", position.X, position.Y)
+}
+
+/**
+ * The input of a game turn for the player to move: for each opponent in the order of the turns starting after it,
+ * the coordinates of its pawn and of the tiles it removed in its last action,
+ * one tile on CodinGame and board.GameRules.Removals tiles in the variants.
+ */
+func (r *Referee) GetTurnInput() string {
+	var input strings.Builder
+
+	for offset := uint8(1); offset < board.PlayersCount; offset++ {
+		opponentId := (r.State.PlayerToMove + offset) % board.PlayersCount
+		opponentPosition := r.State.PlayersPosition[opponentId]
+		lastAction := r.LastActions[opponentId]
+
+		fmt.Fprintf(&input, "%d\n%d\n", opponentPosition.X, opponentPosition.Y)
+
+		for i := 0; i < int(board.GameRules.Removals); i++ {
+			if lastAction == nil {
+				input.WriteString("-1\n-1\n")
+			} else {
+				removedTile := board.GetRemovedTiles(lastAction)[i]
+				fmt.Fprintf(&input, "%d\n%d\n", removedTile.X, removedTile.Y)
+			}
+		}
+	}
+
+	return input.String()
+}
+
+/**
+ * The game is over when the player to move can't play a whole turn with a single opponent left, this opponent wins.
+ */
+func (r *Referee) IsOver() bool {
+	return !movegen.CanPlay(&r.State, r.State.PlayerToMove)
+}
+
+func (r *Referee) GetWinner() uint8 {
+	return board.GetNextPlayer(&r.State, r.State.PlayerToMove)
+}
+
+/**
+ * Plays the output of the player to move, an invalid action loses the game.
+ */
+func (r *Referee) Play(output string) error {
+	var a board.Action
+
+	if strings.TrimSpace(strings.SplitN(output, ";", 2)[0]) == "RANDOM" {
+		legalActions := movegen.GetLegalActions(&r.State, r.State.PlayerToMove)
+		if len(legalActions) == 0 {
+			return fmt.Errorf("player %d has no legal action", r.State.PlayerToMove)
+		}
+		a = legalActions[r.Random.Intn(len(legalActions))]
+	} else {
+		var err error
+		a, err = board.ParseAction(output)
+		if err != nil {
+			return fmt.Errorf("player %d: %w", r.State.PlayerToMove, err)
+		}
+	}
+
+	if err := board.ValidateAction(&r.State, &a, r.State.PlayerToMove); err != nil {
+		return fmt.Errorf("player %d: %w", r.State.PlayerToMove, err)
+	}
+
+	r.LastActions[r.State.PlayerToMove] = &a
+	r.State = board.ApplyAction(&r.State, &a)
+	r.LastAction = &a
+
+	for board.GetActivePlayersCount(&r.State) > 2 && !movegen.CanPlay(&r.State, r.State.PlayerToMove) {
+		r.LastActions[r.State.PlayerToMove] = nil
+		r.State = board.EliminatePlayer(&r.State)
+	}
+
+	return nil
+}
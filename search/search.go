@@ -0,0 +1,629 @@
+package search
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+)
+
+const DEFAULT_SEED = 1
+
+/**
+ * The only source of randomness of the engine, seeded so that two searches with the same limits in nodes give the same result.
+ */
+var rng = rand.New(rand.NewSource(DEFAULT_SEED))
+
+func SetSeed(seed int64) {
+	rng = rand.New(rand.NewSource(seed))
+}
+
+// when not 0, each action is searched for this number of nodes instead of using the time, for reproducible results
+var FixedNodes = 0
+
+/**
+ * The limits of the search of an action with a time budget, or a fixed number of nodes in the fixed nodes mode.
+ */
+func GetSearchLimits(budget time.Duration) SearchLimits {
+	if FixedNodes > 0 {
+		return SearchLimits{Nodes: FixedNodes}
+	}
+	return SearchLimits{MoveTime: budget}
+}
+
+func Debug(s string) {
+	fmt.Fprintln(os.Stderr, s)
+}
+
+func DebugAny(message string, any interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", message, any)
+}
+
+/**
+ * Set by minimax when it evaluates a position that is not the end of the game with eval.GetScore.
+ * An iteration that doesn't reach the horizon has searched the whole game tree: its result is exact and deeper ones are the same.
+ */
+var searchHorizonReached bool
+
+func FindBestMove(currentState *board.State, myPlayerId uint8, tm *TimeManager) (bestAction *board.Action, bestScore int) {
+	bestAction = nil
+	bestScore = -1000000
+
+	depthReached := 0
+
+	startNewSearch()
+
+	// iterative deepening
+	for MaxDepth := 1; tm.canStartIteration(MaxDepth); MaxDepth++ {
+		tm.startIteration()
+		searchHorizonReached = false
+
+		depthBestScore, depthBestAction, isTimeOverSkip := searchAspirationWindow(currentState, MaxDepth, myPlayerId, bestAction != nil, bestScore, tm)
+		if isTimeOverSkip {
+			break
+		}
+
+		tm.endIteration(bestAction != nil && depthBestAction != nil && *bestAction != *depthBestAction)
+
+		// the action is in the move stack of the root, the next iteration overwrites it
+		depthBestActionCopy := *depthBestAction
+
+		bestScore = depthBestScore
+		bestAction = &depthBestActionCopy
+		depthReached = MaxDepth
+
+		// show the best move found so far
+		DebugAny(fmt.Sprintf("Depth %d", MaxDepth), fmt.Sprintf("best score: %d, best action: %v", bestScore, bestAction))
+
+		if !searchHorizonReached {
+			Debug("game tree searched to the end")
+			break
+		}
+	}
+
+	DebugAny("Depth reached", depthReached)
+	DebugAny("Aspiration windows", aspirationStats)
+
+	return
+}
+
+/**
+ * The half width of the window around the score of the previous depth with which an iteration is searched, 0 for a full window.
+ * When the score falls outside, the window grows by ASPIRATION_GROWTH on that side and the depth is searched again.
+ * Off by default, turned on with -aspiration.
+ */
+var AspirationWindow = 0
+
+const ASPIRATION_GROWTH = 4
+
+/**
+ * How often the iterations of FindBestMove are searched again, counted since the start of the engine.
+ */
+type AspirationStats struct {
+	Iterations int
+	FailHighs  int
+	FailLows   int
+}
+
+func (stats AspirationStats) String() string {
+	return fmt.Sprintf("%d re-searches in %d iterations (%d fail high, %d fail low)", stats.FailHighs+stats.FailLows, stats.Iterations, stats.FailHighs, stats.FailLows)
+}
+
+var aspirationStats AspirationStats
+
+/**
+ * Searches a depth with an aspiration window around previousScore when there is one, and searches it again with a wider window
+ * until the score is inside: outside of the window, the score is only a bound.
+ */
+func searchAspirationWindow(currentState *board.State, depth int, myPlayerId uint8, hasPreviousScore bool, previousScore int, tm *TimeManager) (bestScore int, bestAction *board.Action, isTimeOverSkip bool) {
+	alpha := -1000000
+	beta := 1000000
+
+	window := AspirationWindow
+	if window > 0 && hasPreviousScore {
+		alpha = max(previousScore-window, -1000000)
+		beta = min(previousScore+window, 1000000)
+	}
+
+	aspirationStats.Iterations++
+
+	for {
+		bestScore, bestAction, isTimeOverSkip = minimax(currentState, depth, 0, myPlayerId, alpha, beta, tm)
+		if isTimeOverSkip {
+			return 0, nil, true
+		}
+
+		window *= ASPIRATION_GROWTH
+
+		if bestScore <= alpha && alpha > -1000000 {
+			aspirationStats.FailLows++
+			alpha = max(bestScore-window, -1000000)
+		} else if bestScore >= beta && beta < 1000000 {
+			aspirationStats.FailHighs++
+			beta = min(bestScore+window, 1000000)
+		} else {
+			return bestScore, bestAction, false
+		}
+	}
+}
+
+type SearchResult struct {
+	Action board.Action
+	// score from the point of view of the player to move
+	Score int
+	// last depth completed for this action
+	Depth int
+	// principal variation starting with action
+	PV []board.Action
+}
+
+/**
+ * Multi-PV search: returns the multiPV best root actions (all of them if multiPV is 0) with exact scores, best first.
+ * At each depth, the best action is searched with a full window, then excluded from the root to find the next best one, and so on.
+ */
+func FindBestMoves(currentState *board.State, multiPV int, tm *TimeManager) []SearchResult {
+	rootActions := movegen.GetPossibleActions(currentState, currentState.PlayerToMove)
+
+	if multiPV == 0 || multiPV > len(rootActions) {
+		multiPV = len(rootActions)
+	}
+
+	results := make([]SearchResult, 0, multiPV)
+
+	startNewSearch()
+
+	for depth := 1; tm.canStartIteration(depth); depth++ {
+		tm.startIteration()
+		searchHorizonReached = false
+
+		// search the best actions of the previous depth first
+		sort.SliceStable(rootActions, func(i, j int) bool {
+			return getResultRank(results, rootActions[i]) < getResultRank(results, rootActions[j])
+		})
+
+		depthResults := make([]SearchResult, 0, multiPV)
+		excludedActions := make([]board.Action, 0, multiPV)
+
+		for len(depthResults) < multiPV {
+			score, bestAction, isTimeOverSkip := searchRoot(currentState, rootActions, excludedActions, depth, tm)
+			if isTimeOverSkip || bestAction == nil {
+				break
+			}
+
+			pv := append([]board.Action(nil), pvTable[0][:pvLength[0]]...)
+			depthResults = append(depthResults, SearchResult{*bestAction, score, depth, pv})
+			excludedActions = append(excludedActions, *bestAction)
+		}
+
+		completed := len(depthResults) == multiPV
+		if completed {
+			tm.endIteration(len(results) > 0 && results[0].Action != depthResults[0].Action)
+		}
+
+		// the lines not completed at this depth keep their result of the previous depth
+		for _, result := range results {
+			if len(depthResults) < multiPV && getResultRank(depthResults, result.Action) == len(depthResults) {
+				depthResults = append(depthResults, result)
+			}
+		}
+
+		results = depthResults
+
+		if len(results) > 0 {
+			DebugAny(fmt.Sprintf("Multi-PV depth %d", depth), fmt.Sprintf("best score: %d, best action: %v", results[0].Score, results[0].Action))
+		}
+
+		if !completed || !searchHorizonReached {
+			break
+		}
+	}
+
+	return results
+}
+
+func getResultRank(results []SearchResult, a board.Action) int {
+	for i := range results {
+		if results[i].Action == a {
+			return i
+		}
+	}
+	return len(results)
+}
+
+/**
+ * Searches the root actions that are not excluded with a full window and returns the best one with its exact score,
+ * from the point of view of the player to move.
+ */
+func searchRoot(currentState *board.State, rootActions []board.Action, excludedActions []board.Action, depth int, tm *TimeManager) (bestScore int, bestAction *board.Action, isTimeOverSkip bool) {
+	alpha := -1000000
+	beta := 1000000
+
+	pvLength[0] = 0
+
+	for i := range rootActions {
+		if board.GetActionIndex(excludedActions, rootActions[i]) != -1 {
+			continue
+		}
+
+		nextState := board.ApplyAction(currentState, &rootActions[i])
+
+		value, _, isTimeOverSkip := minimax(&nextState, depth-1, 1, currentState.PlayerToMove, alpha, beta, tm)
+		if isTimeOverSkip {
+			return 0, nil, true
+		}
+
+		if bestAction == nil || value > bestScore {
+			bestScore = value
+			bestAction = &rootActions[i]
+			updatePrincipalVariation(0, bestAction)
+			alpha = max(alpha, value)
+		}
+	}
+
+	return bestScore, bestAction, false
+}
+
+const MAX_PLY = board.MAX_PLIES
+
+/**
+ * The principal variation found by minimax, pvTable[ply][:pvLength[ply]] is the best line from the node searched at ply.
+ */
+var pvTable [MAX_PLY + 1][MAX_PLY + 1]board.Action
+var pvLength [MAX_PLY + 1]int
+
+/**
+ * The actions generated by minimax at each ply and the states it searches, a local state passed to minimax would escape to the heap.
+ */
+var actionsStack board.ActionsStack
+var statesStack [MAX_PLY + 2]board.State
+
+func updatePrincipalVariation(ply int, bestMove *board.Action) {
+	pvTable[ply][0] = *bestMove
+	copy(pvTable[ply][1:], pvTable[ply+1][:pvLength[ply+1]])
+	pvLength[ply] = pvLength[ply+1] + 1
+}
+
+/**
+ * Scores are from the point of view of myPlayerId, who is the maximizing player whatever its slot on the board.
+ * With more than two players, all the other players minimize the score (paranoid search),
+ * and an opponent who can't play is eliminated without using a ply of depth.
+ */
+func minimax(currentState *board.State, depth int, ply int, myPlayerId uint8, alpha int, beta int, tm *TimeManager) (bestMoveValue int, bestMove *board.Action, isTimeOverSkip bool) {
+	if tm.shouldStop() {
+		return 0, nil, true
+	}
+
+	pvLength[ply] = 0
+
+	alphaOriginal := alpha
+	betaOriginal := beta
+
+	key, symmetry := getTableKey(currentState, myPlayerId)
+
+	playerId := currentState.PlayerToMove
+	maximizingPlayer := playerId == myPlayerId
+
+	if SingleMoveExtensions && ply > 0 && movegen.GetPossibleActionsCount(currentState, playerId) == 1 {
+		depth++
+	}
+
+	tableMove := board.Action{}
+	hasTableMove := false
+
+	if entry := probeTable(key); entry != nil {
+		// the root is always searched, it must return an action
+		if ply > 0 && int(entry.depth) >= depth && entry.isUsable(alpha, beta) {
+			if entry.depth != SOLVED_DEPTH {
+				searchHorizonReached = true
+			}
+			if entry.hasMove {
+				pvTable[ply][0] = entry.getMove(symmetry)
+				pvLength[ply] = 1
+			}
+			return int(entry.score), nil, false
+		}
+
+		tableMove, hasTableMove = entry.getMove(symmetry), entry.hasMove
+	}
+
+	// todo: merge with no possible action
+	if depth == 0 && !movegen.CanPlay(currentState, playerId) {
+		res := eval.GetScore(currentState, myPlayerId, playerId)
+		storeTable(key, symmetry, res, SOLVED_DEPTH, BOUND_EXACT, nil)
+		return res, nil, false
+	}
+
+	if depth == 0 {
+		res, isTimeOverSkip := quiescence(currentState, QuiescencePlies, ply, myPlayerId, alpha, beta, tm)
+		if isTimeOverSkip {
+			return 0, nil, true
+		}
+
+		// the score of a searched tactical position is only a bound outside of the window
+		bound := BOUND_EXACT
+		if QuiescencePlies > 0 && res <= alpha {
+			bound = BOUND_UPPER
+		} else if QuiescencePlies > 0 && res >= beta {
+			bound = BOUND_LOWER
+		}
+		storeTable(key, symmetry, res, 0, bound, nil)
+		return res, nil, false
+	}
+
+	possibleActions := actionsStack.Keep(ply, movegen.GenerateActions(currentState, playerId, actionsStack.Reuse(ply)))
+
+	if len(possibleActions) == 0 && !maximizingPlayer && board.GetActivePlayersCount(currentState) > 2 {
+		nextState := &statesStack[ply+1]
+		*nextState = board.EliminatePlayer(currentState)
+		value, _, isTimeOverSkip := minimax(nextState, depth, ply+1, myPlayerId, alpha, beta, tm)
+		if isTimeOverSkip {
+			return 0, nil, true
+		}
+
+		// the elimination is not an action, the principal variation goes on with the next player
+		copy(pvTable[ply][:], pvTable[ply+1][:pvLength[ply+1]])
+		pvLength[ply] = pvLength[ply+1]
+		return value, nil, false
+	}
+
+	if len(possibleActions) == 0 {
+		res := eval.GetScore(currentState, myPlayerId, playerId)
+		storeTable(key, symmetry, res, SOLVED_DEPTH, BOUND_EXACT, nil)
+		return res, nil, false
+	}
+
+	// ordering moves by random
+	rng.Shuffle(len(possibleActions), func(i, j int) {
+		possibleActions[i], possibleActions[j] = possibleActions[j], possibleActions[i]
+	})
+
+	// the best action found by a previous search of this state first
+	if hasTableMove {
+		if i := board.GetActionIndex(possibleActions, tableMove); i > 0 {
+			possibleActions[0], possibleActions[i] = possibleActions[i], possibleActions[0]
+		}
+	}
+
+	// tells if the horizon is reached in the subtree of this state, if not its value holds at any depth
+	horizonReachedBefore := searchHorizonReached
+	searchHorizonReached = false
+
+	if maximizingPlayer {
+		bestMoveValue = -1000000
+		bestMove = nil
+
+		for i := 0; i < len(possibleActions); i++ {
+			nextState := &statesStack[ply+1]
+			*nextState = board.ApplyAction(currentState, &possibleActions[i])
+			reduction := getReduction(depth, i)
+			value, _, isTimeOverSkip := minimax(nextState, depth-1-reduction, ply+1, myPlayerId, alpha, beta, tm)
+
+			// the reduced search only tells that the action is worse than the best one so far
+			if !isTimeOverSkip && reduction > 0 && value > alpha {
+				value, _, isTimeOverSkip = minimax(nextState, depth-1, ply+1, myPlayerId, alpha, beta, tm)
+			}
+
+			if isTimeOverSkip {
+				return 0, nil, true
+			}
+
+			if value > bestMoveValue {
+				bestMoveValue = value
+				bestMove = &possibleActions[i]
+				updatePrincipalVariation(ply, bestMove)
+			}
+
+			// Update alpha value
+			alpha = max(alpha, bestMoveValue)
+
+			// Perform alpha-beta pruning
+			if beta <= alpha {
+				//debugAny(fmt.Sprintf("pruning at depth %d", depth), fmt.Sprintf("alpha: %d, beta: %d", alpha, beta))
+				break
+			}
+		}
+	} else {
+		bestMoveValue = 1000000
+		bestMove = nil
+
+		for i := 0; i < len(possibleActions); i++ {
+			nextState := &statesStack[ply+1]
+			*nextState = board.ApplyAction(currentState, &possibleActions[i])
+			reduction := getReduction(depth, i)
+			value, _, isTimeOverSkip := minimax(nextState, depth-1-reduction, ply+1, myPlayerId, alpha, beta, tm)
+
+			// the reduced search only tells that the action is worse than the best one so far
+			if !isTimeOverSkip && reduction > 0 && value < beta {
+				value, _, isTimeOverSkip = minimax(nextState, depth-1, ply+1, myPlayerId, alpha, beta, tm)
+			}
+
+			if isTimeOverSkip {
+				return 0, nil, true
+			}
+
+			if value < bestMoveValue {
+				bestMoveValue = value
+				bestMove = &possibleActions[i]
+				updatePrincipalVariation(ply, bestMove)
+			}
+
+			// Update beta value
+			beta = min(beta, bestMoveValue)
+
+			// Perform alpha-beta pruning
+			if beta <= alpha {
+				//debugAny(fmt.Sprintf("pruning at depth %d", depth), fmt.Sprintf("alpha: %d, beta: %d", alpha, beta))
+				break
+			}
+		}
+	}
+
+	storedDepth := depth
+	if !searchHorizonReached {
+		storedDepth = SOLVED_DEPTH
+	}
+	searchHorizonReached = searchHorizonReached || horizonReachedBefore
+
+	// a value outside of the window is only a bound of the value of the state
+	bound := BOUND_EXACT
+	if bestMoveValue <= alphaOriginal {
+		bound = BOUND_UPPER
+	} else if bestMoveValue >= betaOriginal {
+		bound = BOUND_LOWER
+	}
+	storeTable(key, symmetry, bestMoveValue, storedDepth, bound, bestMove)
+
+	return bestMoveValue, bestMove, false
+}
+
+/**
+ * Late move reductions: from a depth of LMR_MIN_DEPTH, the actions ordered after the first LMR_FULL_DEPTH_ACTIONS are searched
+ * a ply shallower, and searched again at full depth when they improve the value of the player to move.
+ * Off by default, turned on with -lmr.
+ */
+var LateMoveReductions = false
+
+const LMR_MIN_DEPTH = 4
+const LMR_FULL_DEPTH_ACTIONS = 4
+
+func getReduction(depth int, actionIndex int) int {
+	if LateMoveReductions && depth >= LMR_MIN_DEPTH && actionIndex >= LMR_FULL_DEPTH_ACTIONS {
+		return 1
+	}
+	return 0
+}
+
+/**
+ * Searches a ply deeper the positions where the player to move has a single move, like the check extensions of chess:
+ * the pawn may be trapped in the next plies. The game ends before the extensions make the search endless.
+ * Off by default, turned on with -extensions.
+ */
+var SingleMoveExtensions = false
+
+/**
+ * The plies searched after the horizon in the tactical positions, 0 evaluates the horizon directly.
+ * Off by default, turned on with -quiescence.
+ */
+var QuiescencePlies = 0
+
+// a pawn with at most this number of moves may be trapped in the next plies
+const QUIESCENCE_MOBILITY = 2
+
+/**
+ * The score of a position at the horizon of minimax, which the player to move can play. It is eval.GetScore unless the position
+ * is tactical: the player to move or the next player has at most QUIESCENCE_MOBILITY moves, or removing a single tile would
+ * separate them. Then the forcing actions are searched for up to plies more: every action when the player to move is short of moves,
+ * else the actions removing a move of the next player short of moves or a separating tile, against the score of the position
+ * since the player to move can choose a quiet action instead. The games of more than two players are evaluated directly.
+ */
+func quiescence(currentState *board.State, plies int, ply int, myPlayerId uint8, alpha int, beta int, tm *TimeManager) (bestValue int, isTimeOverSkip bool) {
+	playerId := currentState.PlayerToMove
+	score := eval.GetScore(currentState, myPlayerId, playerId)
+
+	if !movegen.CanPlay(currentState, playerId) {
+		return score, false
+	}
+
+	searchHorizonReached = true
+
+	if plies == 0 || board.GetActivePlayersCount(currentState) > 2 {
+		return score, false
+	}
+
+	nextPlayerId := board.GetNextPlayer(currentState, playerId)
+	isEvasion := movegen.GetPossibleActionsCount(currentState, playerId) <= QUIESCENCE_MOBILITY
+
+	forcingTiles := eval.GetSeparatingTiles(currentState, playerId, nextPlayerId)
+	if movegen.GetPossibleActionsCount(currentState, nextPlayerId) <= QUIESCENCE_MOBILITY {
+		for _, tile := range *board.GetAdjacentTiles(currentState.PlayersPosition[nextPlayerId]) {
+			if board.IsTileFree(currentState, &tile) {
+				forcingTiles.Set(board.TileIndex(tile), true)
+			}
+		}
+	}
+
+	if !isEvasion && forcingTiles.Count() == 0 {
+		return score, false
+	}
+
+	var possibleActions []board.Action
+	if isEvasion {
+		possibleActions = movegen.GenerateActions(currentState, playerId, actionsStack.Reuse(ply))
+	} else {
+		possibleActions = movegen.GenerateLegalActions(currentState, playerId, actionsStack.Reuse(ply))
+	}
+	actionsStack.Keep(ply, possibleActions)
+
+	maximizingPlayer := playerId == myPlayerId
+
+	// standing pat on the score of the position, unless every action has to be searched
+	bestValue = score
+	if isEvasion && maximizingPlayer {
+		bestValue = -1000000
+	} else if isEvasion {
+		bestValue = 1000000
+	}
+
+	if maximizingPlayer {
+		alpha = max(alpha, bestValue)
+	} else {
+		beta = min(beta, bestValue)
+	}
+
+	for i := 0; i < len(possibleActions) && alpha < beta; i++ {
+		if !isEvasion && !isForcingAction(&possibleActions[i], &forcingTiles) {
+			continue
+		}
+
+		if tm.shouldStop() {
+			return 0, true
+		}
+
+		nextState := &statesStack[ply+1]
+		*nextState = board.ApplyAction(currentState, &possibleActions[i])
+		value, isTimeOverSkip := quiescence(nextState, plies-1, ply+1, myPlayerId, alpha, beta, tm)
+		if isTimeOverSkip {
+			return 0, true
+		}
+
+		if maximizingPlayer {
+			bestValue = max(bestValue, value)
+			alpha = max(alpha, bestValue)
+		} else {
+			bestValue = min(bestValue, value)
+			beta = min(beta, bestValue)
+		}
+	}
+
+	return bestValue, false
+}
+
+func isForcingAction(a *board.Action, forcingTiles *board.CompactBoolArray) bool {
+	for _, tile := range board.GetRemovedTiles(a) {
+		if forcingTiles.Get(board.TileIndex(tile)) {
+			return true
+		}
+	}
+	return false
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,469 @@
+package search
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"isola/board"
+	"isola/movegen"
+)
+
+func TestSearchWithoutLimitsSolvesEndgame(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	currentState, err := board.ParsePosition("A.#######/..#######/.########/#########/#########/#########/#########/########./#######.B 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bestAction, bestScore := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), SearchLimits{}))
+
+	if bestAction == nil || bestScore < 1000000/2 {
+		t.Errorf("got %v with score %d, want a win for player 0", bestAction, bestScore)
+	}
+}
+
+func TestSearchNodeLimit(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	currentState := board.InitialState()
+	tm := NewTimeManager(time.Now(), SearchLimits{Nodes: 5000})
+
+	if bestAction, _ := FindBestMove(&currentState, 0, tm); bestAction == nil {
+		t.Fatal("no action found")
+	}
+
+	if tm.nodes != 5000 {
+		t.Errorf("searched %d nodes, want 5000", tm.nodes)
+	}
+}
+
+func BenchmarkSearchFixedNodes(b *testing.B) {
+	board.InitAdjacentTilesCache()
+
+	for i := 0; i < b.N; i++ {
+		currentState := board.InitialState()
+		FindBestMove(&currentState, 0, NewTimeManager(time.Now(), SearchLimits{Nodes: 100000}))
+	}
+}
+
+func TestSearchIsReproducible(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer SetSeed(DEFAULT_SEED)
+
+	search := func() []SearchResult {
+		SetSeed(42)
+		currentState := board.InitialState()
+		return FindBestMoves(&currentState, 3, NewTimeManager(time.Now(), SearchLimits{Nodes: 20000}))
+	}
+
+	first := search()
+	second := search()
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("two searches with the same seed and node limit differ:\n%v\n%v", first, second)
+	}
+}
+
+/**
+ * The multi-PV search gives the best actions in order with the exact score of each one: the score of a full window search
+ * of the action alone at the same depth.
+ */
+func TestMultiPVScoresAreExact(t *testing.T) {
+	board.InitAdjacentTilesCache()
+
+	currentState, err := board.ParsePosition("........./........./..#....../........./A.......B/........./......#../........./......... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := FindBestMoves(&currentState, 4, NewTimeManager(time.Now(), SearchLimits{Depth: 4}))
+	if len(results) != 4 {
+		t.Fatalf("%d results, want 4", len(results))
+	}
+
+	for i, result := range results {
+		if i > 0 && result.Score > results[i-1].Score {
+			t.Errorf("result %d: score %d after %d", i, result.Score, results[i-1].Score)
+		}
+
+		score, _, _ := searchRoot(&currentState, []board.Action{result.Action}, nil, result.Depth, NewTimeManager(time.Now(), SearchLimits{}))
+		if result.Depth != 4 || score != result.Score {
+			t.Errorf("%v: score %d at depth %d, %d with a full window at depth 4", result.Action, result.Score, result.Depth, score)
+		}
+	}
+}
+
+/**
+ * Searches the initial position at a fixed depth, the move stacks and the transposition table are preallocated.
+ */
+func searchWithoutAllocation(tm *TimeManager) {
+	currentState := board.InitialState()
+	startNewSearch()
+	minimax(&currentState, 4, 0, 0, -1000000, 1000000, tm)
+}
+
+func TestSearchDoesNotAllocatePerNode(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(plies int) { QuiescencePlies = plies }(QuiescencePlies)
+
+	for _, QuiescencePlies = range []int{0, 4} {
+		tm := NewTimeManager(time.Now(), SearchLimits{})
+
+		allocs := testing.AllocsPerRun(5, func() {
+			searchWithoutAllocation(tm)
+		})
+
+		if allocs > 0 {
+			t.Errorf("%.0f allocations to search %d nodes with %d plies of quiescence search, want none", allocs, tm.nodes, QuiescencePlies)
+		}
+	}
+}
+
+func BenchmarkSearchNodes(b *testing.B) {
+	board.InitAdjacentTilesCache()
+
+	tm := NewTimeManager(time.Now(), SearchLimits{})
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		searchWithoutAllocation(tm)
+	}
+
+	b.ReportMetric(float64(tm.nodes)/float64(b.N), "nodes/op")
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(tm.nodes), "ns/node")
+}
+
+/**
+ * A position and its symmetric ones share their entries of the transposition table:
+ * they get the same score at the same depth, and a legal action transformed back from the representative.
+ * The late move reductions depend on the random order of the actions, they are turned off.
+ */
+func TestSearchOfSymmetricPositions(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(reductions bool) { LateMoveReductions = reductions }(LateMoveReductions)
+	LateMoveReductions = false
+
+	currentState, err := board.ParsePosition("........./........./..#....../........./A.......B/........./......#../........./......... 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := SearchLimits{Depth: 3}
+
+	_, score := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), limits))
+
+	for _, symmetry := range board.GetSymmetries()[1:] {
+		transformedState := board.TransformState(&currentState, symmetry)
+		myPlayerId := board.TransformPlayer(0, symmetry)
+
+		bestAction, transformedScore := FindBestMove(&transformedState, myPlayerId, NewTimeManager(time.Now(), limits))
+		if transformedScore != score {
+			t.Errorf("%v: score %d, want %d", symmetry, transformedScore, score)
+		}
+		if bestAction == nil || board.ValidateAction(&transformedState, bestAction, myPlayerId) != nil {
+			t.Errorf("%v: illegal action %v in %s", symmetry, bestAction, board.FormatPosition(&transformedState))
+		}
+	}
+}
+
+/**
+ * Player 1 has two moves in the corner: removing (8, 7) forces it to (7, 8), from where its last move is removed.
+ * A search of one ply only finds the trap with the quiescence search of the horizon.
+ */
+func TestQuiescenceFindsTrap(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(plies int) { QuiescencePlies = plies }(QuiescencePlies)
+
+	currentState, err := board.ParsePosition("........./........./........./........./A......../........./........./......##./......#.B 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := SearchLimits{Depth: 1}
+
+	QuiescencePlies = 0
+	if _, score := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), limits)); score > 1000000/4 {
+		t.Fatalf("score %d without quiescence search, the trap is beyond the horizon", score)
+	}
+
+	QuiescencePlies = 4
+	bestAction, score := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), limits))
+	if score < 1000000/4 || bestAction == nil || bestAction.RemoveTile != (board.Coord{X: 8, Y: 7}) {
+		t.Fatalf("%v with score %d, want the trap removing (8, 7)", bestAction, score)
+	}
+}
+
+/**
+ * The trap of TestQuiescenceFindsTrap is found by a search of two plies when it extends the move of player 1, left with a single move.
+ */
+func TestSingleMoveExtensionFindsTrap(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(extensions bool) { SingleMoveExtensions = extensions }(SingleMoveExtensions)
+
+	currentState, err := board.ParsePosition("........./........./........./........./A......../........./........./......##./......#.B 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits := SearchLimits{Depth: 2}
+
+	SingleMoveExtensions = false
+	if _, score := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), limits)); score > 1000000/4 {
+		t.Fatalf("score %d without extensions, the trap is beyond the horizon", score)
+	}
+
+	SingleMoveExtensions = true
+	bestAction, score := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), limits))
+	if score < 1000000/4 || bestAction == nil || bestAction.RemoveTile != (board.Coord{X: 8, Y: 7}) {
+		t.Fatalf("%v with score %d, want the trap removing (8, 7)", bestAction, score)
+	}
+}
+
+/**
+ * The reductions search fewer nodes to the same depth, and the actions they skip are searched again when they are better:
+ * the endgame is still solved.
+ */
+func TestLateMoveReductions(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(reductions bool) { LateMoveReductions = reductions }(LateMoveReductions)
+
+	nodes := [2]int{}
+	for i, reductions := range []bool{false, true} {
+		LateMoveReductions = reductions
+		currentState := board.InitialState()
+		tm := NewTimeManager(time.Now(), SearchLimits{Depth: LMR_MIN_DEPTH + 1})
+		if bestAction, _ := FindBestMove(&currentState, 0, tm); bestAction == nil {
+			t.Fatal("no action found")
+		}
+		nodes[i] = tm.nodes
+	}
+	if nodes[1] >= nodes[0] {
+		t.Errorf("searched %d nodes with the reductions, want less than %d without", nodes[1], nodes[0])
+	}
+
+	currentState, err := board.ParsePosition("A.#######/..#######/.########/#########/#########/#########/#########/########./#######.B 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bestAction, bestScore := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), SearchLimits{})); bestAction == nil || bestScore < 1000000/2 {
+		t.Errorf("got %v with score %d, want a win for player 0", bestAction, bestScore)
+	}
+}
+
+/**
+ * The windows around the score of the previous depth fail high when the win is found, and the depth is searched again
+ * until the win is inside: the endgame is still solved.
+ */
+func TestAspirationWindows(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(window int) { AspirationWindow = window }(AspirationWindow)
+	defer func(stats AspirationStats) { aspirationStats = stats }(aspirationStats)
+
+	currentState, err := board.ParsePosition("A.#######/..#######/.########/#########/#########/#########/#########/########./#######.B 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	AspirationWindow = 1
+	aspirationStats = AspirationStats{}
+
+	if bestAction, bestScore := FindBestMove(&currentState, 0, NewTimeManager(time.Now(), SearchLimits{})); bestAction == nil || bestScore < 1000000/2 {
+		t.Errorf("got %v with score %d, want a win for player 0", bestAction, bestScore)
+	}
+	if aspirationStats.Iterations == 0 || aspirationStats.FailHighs == 0 {
+		t.Errorf("%v, want a fail high when the win is found", aspirationStats)
+	}
+}
+
+/**
+ * A window of 1 fails on both sides in random positions, and the depth searched again gets the score of a full window.
+ */
+func TestAspirationWindowsKeepScores(t *testing.T) {
+	board.InitAdjacentTilesCache()
+	defer func(window int) { AspirationWindow = window }(AspirationWindow)
+	defer func(stats AspirationStats) { aspirationStats = stats }(aspirationStats)
+	defer SetSeed(DEFAULT_SEED)
+	defer SetTableSize(DEFAULT_TABLE_SIZE_MB)
+
+	random := rand.New(rand.NewSource(1))
+	aspirationStats = AspirationStats{}
+
+	for i := 0; i < 10; i++ {
+		currentState := movegen.RandomPosition(random)
+		if !movegen.CanPlay(&currentState, currentState.PlayerToMove) {
+			continue
+		}
+
+		scores := [2]int{}
+		for j, window := range []int{0, 1} {
+			AspirationWindow = window
+			SetSeed(DEFAULT_SEED)
+			SetTableSize(DEFAULT_TABLE_SIZE_MB)
+			_, scores[j] = FindBestMove(&currentState, currentState.PlayerToMove, NewTimeManager(time.Now(), SearchLimits{Depth: 4}))
+		}
+		if scores[1] != scores[0] {
+			t.Errorf("%s: score %d with an aspiration window, %d with a full window", board.FormatPosition(&currentState), scores[1], scores[0])
+		}
+	}
+
+	if aspirationStats.FailHighs == 0 || aspirationStats.FailLows == 0 {
+		t.Errorf("%v, want fail highs and fail lows", aspirationStats)
+	}
+}
+
+/**
+ * Replaces the clock of the time manager with one that only moves with the returned function, and counts its reads.
+ */
+func setFakeClock(t *testing.T) (startedAt time.Time, advance func(d time.Duration), reads *int) {
+	startedAt = time.Now()
+	current := startedAt
+	reads = new(int)
+
+	clock = func() time.Time {
+		*reads++
+		return current
+	}
+	t.Cleanup(func() { clock = time.Now })
+
+	return startedAt, func(d time.Duration) { current = current.Add(d) }, reads
+}
+
+/**
+ * Runs an iteration of the given nodes and duration on the time manager.
+ */
+func runIteration(tm *TimeManager, nodes int, duration time.Duration, advance func(d time.Duration), bestActionChanged bool) {
+	tm.startIteration()
+	tm.nodes += nodes
+	advance(duration)
+	tm.endIteration(bestActionChanged)
+}
+
+func TestTimeManagerSoftLimit(t *testing.T) {
+	startedAt, advance, _ := setFakeClock(t)
+
+	// a hard limit of 100ms and a soft limit of 50ms
+	tm := NewTimeManager(startedAt, SearchLimits{MoveTime: 100*time.Millisecond + SAFETY_MARGIN})
+
+	advance(49 * time.Millisecond)
+	if !tm.canStartIteration(1) {
+		t.Error("no first iteration before the soft limit")
+	}
+
+	advance(time.Millisecond)
+	if tm.canStartIteration(1) {
+		t.Error("first iteration started at the soft limit")
+	}
+}
+
+/**
+ * A depth is not started when the branching factor of the previous ones predicts that it ends after the hard limit.
+ */
+func TestTimeManagerPredictsIterations(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodes      int
+		wantResult bool
+	}{
+		// 10ms * 2 = 20ms predicted after 15ms
+		{"branching factor 2", 200, true},
+		// 10ms * 10 = 100ms predicted after 15ms
+		{"branching factor 10", 1000, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			startedAt, advance, _ := setFakeClock(t)
+			tm := NewTimeManager(startedAt, SearchLimits{MoveTime: 100*time.Millisecond + SAFETY_MARGIN})
+
+			runIteration(tm, 100, 5*time.Millisecond, advance, false)
+			runIteration(tm, test.nodes, 10*time.Millisecond, advance, false)
+
+			if tm.branchingFactor != float64(test.nodes)/100 {
+				t.Errorf("branching factor %.1f, want %.1f", tm.branchingFactor, float64(test.nodes)/100)
+			}
+			if got := tm.canStartIteration(3); got != test.wantResult {
+				t.Errorf("can start the third iteration = %v, want %v", got, test.wantResult)
+			}
+		})
+	}
+}
+
+/**
+ * After the first depth, no branching factor is measured yet: the second depth is predicted with DEFAULT_BRANCHING_FACTOR.
+ */
+func TestTimeManagerPredictsSecondIteration(t *testing.T) {
+	tests := []struct {
+		name       string
+		duration   time.Duration
+		wantResult bool
+	}{
+		// 5ms + 5ms * 8 = 45ms
+		{"short first depth", 5 * time.Millisecond, true},
+		// 12ms + 12ms * 8 = 108ms
+		{"long first depth", 12 * time.Millisecond, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			startedAt, advance, _ := setFakeClock(t)
+			tm := NewTimeManager(startedAt, SearchLimits{MoveTime: 100*time.Millisecond + SAFETY_MARGIN})
+
+			runIteration(tm, 100, test.duration, advance, false)
+
+			if tm.branchingFactor != 0 {
+				t.Errorf("branching factor %.1f after a single depth, want none", tm.branchingFactor)
+			}
+			if got := tm.canStartIteration(2); got != test.wantResult {
+				t.Errorf("can start the second iteration = %v, want %v", got, test.wantResult)
+			}
+		})
+	}
+}
+
+/**
+ * Each change of the best action between two depths moves the soft limit by a quarter of the budget, up to the hard limit.
+ */
+func TestTimeManagerExtendsSoftLimit(t *testing.T) {
+	startedAt, advance, _ := setFakeClock(t)
+
+	tm := NewTimeManager(startedAt, SearchLimits{MoveTime: 100*time.Millisecond + SAFETY_MARGIN})
+
+	runIteration(tm, 100, 55*time.Millisecond, advance, false)
+	if tm.softLimit != 50*time.Millisecond || tm.canStartIteration(2) {
+		t.Fatalf("soft limit %v, want 50ms without a change of the best action", tm.softLimit)
+	}
+
+	runIteration(tm, 100, 0, advance, true)
+	if tm.softLimit != 75*time.Millisecond || !tm.canStartIteration(3) {
+		t.Errorf("soft limit %v, want 75ms after a change of the best action", tm.softLimit)
+	}
+
+	runIteration(tm, 100, 0, advance, true)
+	runIteration(tm, 100, 0, advance, true)
+	if tm.softLimit != 100*time.Millisecond {
+		t.Errorf("soft limit %v, want the hard limit of 100ms", tm.softLimit)
+	}
+}
+
+/**
+ * The clock is read once every TIME_CHECK_INTERVAL nodes: the search is aborted at the first read after the hard limit.
+ */
+func TestTimeManagerChecksClockEveryInterval(t *testing.T) {
+	startedAt, advance, reads := setFakeClock(t)
+
+	tm := NewTimeManager(startedAt, SearchLimits{MoveTime: 100*time.Millisecond + SAFETY_MARGIN})
+	advance(100 * time.Millisecond)
+
+	for i := 1; i < TIME_CHECK_INTERVAL; i++ {
+		if tm.shouldStop() {
+			t.Fatalf("stopped at node %d, before reading the clock", i)
+		}
+	}
+	if *reads != 0 {
+		t.Errorf("clock read %d times in %d nodes", *reads, TIME_CHECK_INTERVAL-1)
+	}
+
+	if !tm.shouldStop() || *reads != 1 {
+		t.Errorf("not stopped at node %d after the hard limit, clock read %d times", TIME_CHECK_INTERVAL, *reads)
+	}
+}
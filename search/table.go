@@ -0,0 +1,118 @@
+package search
+
+import (
+	"math"
+	"unsafe"
+
+	"isola/board"
+)
+
+const (
+	// the score is the value of the state
+	BOUND_EXACT uint8 = iota
+	// the value of the state is at least the score
+	BOUND_LOWER
+	// the value of the state is at most the score
+	BOUND_UPPER
+)
+
+// depth of the entries whose subtree reaches the end of the game everywhere, their score holds at any depth
+const SOLVED_DEPTH = math.MaxInt8
+
+type tableEntry struct {
+	key        uint64
+	score      int32
+	move       board.Action
+	depth      int8
+	bound      uint8
+	generation uint8
+	hasMove    bool
+}
+
+const DEFAULT_TABLE_SIZE_MB = 64
+
+/**
+ * Transposition table of fixed size, indexed by the low bits of the key. An entry is always replaced by the last state stored at its index.
+ * Only the entries of the current generation are used, a new search starts a new generation instead of clearing the table.
+ */
+var transpositionTable = make([]tableEntry, getTableEntriesCount(DEFAULT_TABLE_SIZE_MB))
+var tableGeneration uint8 = 1
+
+/**
+ * The largest power of two of entries that fits in the memory budget, so that the index is a mask of the key.
+ */
+func getTableEntriesCount(megabytes int) int {
+	entriesCount := 1
+	for (entriesCount*2)*int(unsafe.Sizeof(tableEntry{})) <= megabytes*1024*1024 {
+		entriesCount *= 2
+	}
+	return entriesCount
+}
+
+func SetTableSize(megabytes int) {
+	transpositionTable = make([]tableEntry, getTableEntriesCount(megabytes))
+	tableGeneration = 1
+}
+
+func startNewSearch() {
+	tableGeneration++
+
+	// once in 255 searches, so that an entry of a previous search with the same generation is not used
+	if tableGeneration == 0 {
+		for i := range transpositionTable {
+			transpositionTable[i] = tableEntry{}
+		}
+		tableGeneration = 1
+	}
+}
+
+// scores are from the point of view of the player searching, it is part of the key of the transposition table
+const PERSPECTIVE_KEY = 0x9e3779b97f4a7c15
+
+/**
+ * The symmetric states share the entry of their representative, see board.GetCanonicalHash:
+ * its move is the one of the representative, and its score is from the point of view of the player searching transformed by the symmetry.
+ */
+func getTableKey(currentState *board.State, myPlayerId uint8) (key uint64, symmetry board.Symmetry) {
+	hash, symmetry := board.GetCanonicalHash(currentState)
+
+	// with more than two players, each player has its own key
+	return hash ^ PERSPECTIVE_KEY*uint64(board.TransformPlayer(myPlayerId, symmetry)), symmetry
+}
+
+func probeTable(key uint64) *tableEntry {
+	entry := &transpositionTable[key&uint64(len(transpositionTable)-1)]
+	if entry.generation != tableGeneration || entry.key != key {
+		return nil
+	}
+	return entry
+}
+
+func storeTable(key uint64, symmetry board.Symmetry, score int, depth int, bound uint8, move *board.Action) {
+	entry := &transpositionTable[key&uint64(len(transpositionTable)-1)]
+	*entry = tableEntry{key: key, score: int32(score), depth: int8(depth), bound: bound, generation: tableGeneration}
+	if move != nil {
+		entry.move = board.TransformAction(move, symmetry)
+		entry.hasMove = true
+	}
+}
+
+/**
+ * The move of the entry in the state of the key, transformed back from the representative.
+ */
+func (entry *tableEntry) getMove(symmetry board.Symmetry) board.Action {
+	return board.TransformAction(&entry.move, symmetry)
+}
+
+/**
+ * An entry gives the value of the state for the window if it is exact or if its bound is outside of the window.
+ */
+func (entry *tableEntry) isUsable(alpha int, beta int) bool {
+	switch entry.bound {
+	case BOUND_LOWER:
+		return int(entry.score) >= beta
+	case BOUND_UPPER:
+		return int(entry.score) <= alpha
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package search
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// the clock of the time manager, the tests replace it to control the time
+var clock = time.Now
+
+func getCurrentDuration(startedAt time.Time) time.Duration {
+	return clock().Sub(startedAt)
+}
+
+func isTimeOver(deadline time.Time) bool {
+	return clock().After(deadline)
+}
+
+// kept from the time budget for the pauses of the GC and the OS, and writing the output
+const SAFETY_MARGIN = 10 * time.Millisecond
+
+// the clock is read once every TIME_CHECK_INTERVAL nodes, it must be a power of 2
+const TIME_CHECK_INTERVAL = 256
+
+// part of the time budget after which no new depth is started, when the best action is stable
+const SOFT_LIMIT_RATIO = 0.5
+
+// part of the time budget added to the soft limit each time the best action changes between two depths
+const SOFT_LIMIT_EXTENSION_RATIO = 0.25
+
+// branching factor predicting the second depth, before two depths have measured one: the later depths measure from 2 to 8
+const DEFAULT_BRANCHING_FACTOR = 8
+
+/**
+ * Any combination of limits of a search, a zero value means no limit.
+ * Without any limit, the search stops when the game is solved.
+ */
+type SearchLimits struct {
+	// time budget from the start of the search
+	MoveTime time.Duration
+	// maximum depth of the iterative deepening
+	Depth int
+	// maximum number of nodes, the search is aborted when it is reached, as when the time is over
+	Nodes int
+}
+
+/**
+ * Decides when the iterative deepening stops.
+ * The hard limit aborts the search, it is checked every TIME_CHECK_INTERVAL nodes.
+ * The soft limit stops the search between two depths: no new depth is started after it,
+ * nor when the branching factor of the previous depths predicts that it won't finish before the hard limit.
+ */
+type TimeManager struct {
+	limits SearchLimits
+
+	startedAt time.Time
+	softLimit time.Duration
+	hardLimit time.Duration
+
+	nodes   int
+	stopped bool
+
+	// number of completed iterations, the depth of the result of the search
+	depthReached int
+
+	iterationStartedAt    time.Duration
+	iterationStartNodes   int
+	lastIterationDuration time.Duration
+	lastIterationNodes    int
+	branchingFactor       float64
+}
+
+func NewTimeManager(startedAt time.Time, limits SearchLimits) *TimeManager {
+	hardLimit := limits.MoveTime - SAFETY_MARGIN
+
+	return &TimeManager{
+		limits:    limits,
+		startedAt: startedAt,
+		softLimit: time.Duration(float64(hardLimit) * SOFT_LIMIT_RATIO),
+		hardLimit: hardLimit,
+	}
+}
+
+/**
+ * Counts a node and tells if the search must be aborted.
+ */
+func (tm *TimeManager) shouldStop() bool {
+	tm.nodes++
+
+	if tm.limits.Nodes > 0 && tm.nodes >= tm.limits.Nodes {
+		tm.stopped = true
+	}
+
+	if tm.limits.MoveTime > 0 && tm.nodes&(TIME_CHECK_INTERVAL-1) == 0 && getCurrentDuration(tm.startedAt) >= tm.hardLimit {
+		tm.stopped = true
+	}
+
+	return tm.stopped
+}
+
+func (tm *TimeManager) canStartIteration(depth int) bool {
+	if tm.stopped || (tm.limits.Depth > 0 && depth > tm.limits.Depth) {
+		return false
+	}
+
+	if tm.limits.MoveTime == 0 {
+		return true
+	}
+
+	elapsed := getCurrentDuration(tm.startedAt)
+
+	if elapsed >= tm.softLimit || elapsed >= tm.hardLimit {
+		return false
+	}
+
+	if tm.lastIterationNodes == 0 {
+		return true
+	}
+
+	branchingFactor := tm.branchingFactor
+	if branchingFactor == 0 {
+		branchingFactor = DEFAULT_BRANCHING_FACTOR
+	}
+
+	predictedDuration := time.Duration(float64(tm.lastIterationDuration) * branchingFactor)
+
+	return elapsed+predictedDuration < tm.hardLimit
+}
+
+func (tm *TimeManager) startIteration() {
+	tm.iterationStartedAt = getCurrentDuration(tm.startedAt)
+	tm.iterationStartNodes = tm.nodes
+}
+
+/**
+ * Records the duration of the completed iteration, and gives more time when the best action changed.
+ */
+func (tm *TimeManager) endIteration(bestActionChanged bool) {
+	duration := getCurrentDuration(tm.startedAt) - tm.iterationStartedAt
+	nodes := tm.nodes - tm.iterationStartNodes
+
+	if tm.lastIterationNodes > 0 {
+		tm.branchingFactor = float64(nodes) / float64(tm.lastIterationNodes)
+	}
+
+	tm.lastIterationDuration = duration
+	tm.lastIterationNodes = max(nodes, 1)
+	tm.depthReached++
+
+	if bestActionChanged && tm.limits.MoveTime > 0 {
+		tm.softLimit = time.Duration(math.Min(float64(tm.hardLimit), float64(tm.softLimit)+float64(tm.hardLimit)*SOFT_LIMIT_EXTENSION_RATIO))
+	}
+
+	DebugAny("iteration", fmt.Sprintf("%v, %d nodes, branching factor %.1f, soft limit %v", duration, nodes, tm.branchingFactor, tm.softLimit))
+}
+
+/**
+ * The number of nodes searched so far.
+ */
+func (tm *TimeManager) Nodes() int {
+	return tm.nodes
+}
+
+/**
+ * The depth of the last completed iteration, 0 before the first one.
+ */
+func (tm *TimeManager) DepthReached() int {
+	return tm.depthReached
+}
@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"isola/board"
+	"isola/movegen"
+)
+
+/**
+ * The game-theoretic value of a position for the player to move, with best play of both players.
+ */
+type Result uint8
+
+const (
+	LOSS Result = iota
+	WIN
+)
+
+func (result Result) String() string {
+	if result == WIN {
+		return "win"
+	}
+	return "loss"
+}
+
+/**
+ * Exhaustive solver of the games of two players on small boards, under board.GameRules.
+ * Every legal action is tried, the removals of movegen.GenerateActions can miss the only winning one.
+ * The result of each solved position is kept in Table for all its symmetric positions, a solver is for one board and one set of rules.
+ */
+type Solver struct {
+	Table *ResultTable
+	// the positions solved, the ones found in the table are not counted
+	Nodes int
+}
+
+func NewSolver() *Solver {
+	return &Solver{Table: NewResultTable()}
+}
+
+// the actions tried at each ply and their order, the keys of a ply are reused like the actions of a board.ActionsStack
+var actionsStack board.ActionsStack
+var orderKeysStack [board.MAX_PLIES + 1][]uint8
+var orderedActionsStack board.ActionsStack
+
+// an action is ordered by the mobility of the opponent after it, then by the mobility of the player
+const ORDER_KEYS = (board.MAX_MOVES + 1) * (board.MAX_MOVES + 1)
+
+func (s *Solver) Solve(currentState *board.State) Result {
+	return s.solve(currentState, 0)
+}
+
+func (s *Solver) solve(currentState *board.State, ply int) Result {
+	playerId := currentState.PlayerToMove
+	opponentId := 1 - playerId
+
+	if !movegen.CanPlay(currentState, playerId) {
+		return LOSS
+	}
+
+	// the symmetric positions share the result of their representative
+	hash, _ := board.GetCanonicalHash(currentState)
+	if result, ok := s.Table.Get(hash); ok {
+		return result
+	}
+
+	s.Nodes++
+
+	possibleActions := actionsStack.Keep(ply, movegen.GenerateLegalActions(currentState, playerId, actionsStack.Reuse(ply)))
+
+	// an action after which the opponent can't play wins at once, the others are ordered from the least mobile opponent
+	orderCounts := [ORDER_KEYS + 1]int{}
+	orderKeys := orderKeysStack[ply][:0]
+
+	for i := range possibleActions {
+		nextState := board.ApplyAction(currentState, &possibleActions[i])
+		if !movegen.CanPlay(&nextState, opponentId) {
+			s.Table.Put(hash, WIN)
+			return WIN
+		}
+
+		orderKey := movegen.GetPossibleActionsCount(&nextState, opponentId)*(board.MAX_MOVES+1) + board.MAX_MOVES - movegen.GetPossibleActionsCount(&nextState, playerId)
+		orderKeys = append(orderKeys, uint8(orderKey))
+		orderCounts[orderKey+1]++
+	}
+	orderKeysStack[ply] = orderKeys
+
+	orderedActions := orderedActionsStack.Keep(ply, orderActions(possibleActions, orderKeys, &orderCounts, orderedActionsStack.Reuse(ply)))
+
+	result := LOSS
+	for i := range orderedActions {
+		nextState := board.ApplyAction(currentState, &orderedActions[i])
+		if s.solve(&nextState, ply+1) == LOSS {
+			result = WIN
+			break
+		}
+	}
+
+	s.Table.Put(hash, result)
+	return result
+}
+
+/**
+ * Counting sort of the actions by their keys, orderCounts[key+1] holds the number of actions of each key.
+ */
+func orderActions(actions []board.Action, orderKeys []uint8, orderCounts *[ORDER_KEYS + 1]int, orderedActions []board.Action) []board.Action {
+	for key := 1; key <= ORDER_KEYS; key++ {
+		orderCounts[key] += orderCounts[key-1]
+	}
+
+	for range actions {
+		orderedActions = append(orderedActions, board.Action{})
+	}
+
+	for i := range actions {
+		orderedActions[orderCounts[orderKeys[i]]] = actions[i]
+		orderCounts[orderKeys[i]]++
+	}
+
+	return orderedActions
+}
+
+/**
+ * An action of the player to move that keeps the win, nil if the position is lost.
+ */
+func (s *Solver) GetWinningAction(currentState *board.State) *board.Action {
+	legalActions := movegen.GetLegalActions(currentState, currentState.PlayerToMove)
+
+	// the action found by solving the position is in the table, the other ones may need to be solved
+	for i := range legalActions {
+		nextState := board.ApplyAction(currentState, &legalActions[i])
+		hash, _ := board.GetCanonicalHash(&nextState)
+		if result, ok := s.Table.Get(hash); (ok && result == LOSS) || !movegen.CanPlay(&nextState, nextState.PlayerToMove) {
+			return &legalActions[i]
+		}
+	}
+
+	if s.Solve(currentState) == LOSS {
+		return nil
+	}
+
+	for i := range legalActions {
+		nextState := board.ApplyAction(currentState, &legalActions[i])
+		if s.Solve(&nextState) == LOSS {
+			return &legalActions[i]
+		}
+	}
+	return nil
+}
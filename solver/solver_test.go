@@ -0,0 +1,203 @@
+package solver
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"isola/board"
+	"isola/eval"
+	"isola/movegen"
+	"isola/search"
+)
+
+func setConfig(t *testing.T, notation string) {
+	config, err := board.ParseConfig(notation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := board.SetConfig(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { board.SetConfig(board.DEFAULT_CONFIG) })
+}
+
+/**
+ * Plain negamax without table nor ordering, tells if the player to move wins.
+ */
+func referenceSolve(currentState *board.State) bool {
+	if !movegen.CanPlay(currentState, currentState.PlayerToMove) {
+		return false
+	}
+	for _, a := range movegen.GetLegalActions(currentState, currentState.PlayerToMove) {
+		nextState := board.ApplyAction(currentState, &a)
+		if !referenceSolve(&nextState) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSolverMatchesReference(t *testing.T) {
+	for _, test := range []struct {
+		config string
+		rules  string
+		plies  int
+	}{
+		{"3x3", "king", 0},
+		{"4x3", "king", 2},
+		{"4x4", "king", 6},
+		{"4x4", "orthogonal remove-first", 4},
+		{"5x4", "knight removals=2", 4},
+	} {
+		t.Run(test.config+" "+test.rules, func(t *testing.T) {
+			setConfig(t, test.config)
+			rules, err := board.ParseRules(test.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := board.SetRules(rules); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { board.SetRules(board.DEFAULT_RULES) })
+
+			s := NewSolver()
+			random := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 20; i++ {
+				currentState := board.InitialState()
+				for ply := 0; ply < test.plies && movegen.CanPlay(&currentState, currentState.PlayerToMove); ply++ {
+					legalActions := movegen.GetLegalActions(&currentState, currentState.PlayerToMove)
+					currentState = board.ApplyAction(&currentState, &legalActions[random.Intn(len(legalActions))])
+				}
+
+				result := s.Solve(&currentState)
+				if want := referenceSolve(&currentState); (result == WIN) != want {
+					t.Fatalf("%s is a %v, want a win %v", board.FormatPosition(&currentState), result, want)
+				}
+
+				winningAction := s.GetWinningAction(&currentState)
+				if (winningAction != nil) != (result == WIN) {
+					t.Fatalf("winning action %v of a %v in %s", winningAction, result, board.FormatPosition(&currentState))
+				}
+				if winningAction != nil {
+					nextState := board.ApplyAction(&currentState, winningAction)
+					if referenceSolve(&nextState) {
+						t.Fatalf("%s doesn't win in %s", board.FormatAction(winningAction), board.FormatPosition(&currentState))
+					}
+				}
+			}
+		})
+	}
+}
+
+/**
+ * The winners of the initial positions under the rules of CodinGame, the ground truth of the score of the end of the game.
+ * 5x4 solves 9M positions, it is skipped with -short.
+ */
+func TestSolvedInitialPositions(t *testing.T) {
+	for _, test := range []struct {
+		config   string
+		winnerId uint8
+	}{
+		{"3x3", 0},
+		{"4x3", 0},
+		{"4x4", 0},
+		{"5x4", 0},
+	} {
+		t.Run(test.config, func(t *testing.T) {
+			if test.config == "5x4" && testing.Short() {
+				t.Skip("9M positions")
+			}
+			setConfig(t, test.config)
+
+			s := NewSolver()
+			initial := board.InitialState()
+			winnerId := uint8(0)
+			if s.Solve(&initial) == LOSS {
+				winnerId = 1
+			}
+			if winnerId != test.winnerId {
+				t.Errorf("player %d wins, want player %d", winnerId, test.winnerId)
+			}
+		})
+	}
+}
+
+func TestResultTableFile(t *testing.T) {
+	setConfig(t, "4x3")
+
+	s := NewSolver()
+	initial := board.InitialState()
+	s.Solve(&initial)
+
+	var file bytes.Buffer
+	if err := s.Table.Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Len() > 64+8*s.Table.Len() {
+		t.Errorf("%d bytes for %d positions", file.Len(), s.Table.Len())
+	}
+
+	table, err := ReadResultTable(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Len() != s.Table.Len() {
+		t.Fatalf("%d positions read, want %d", table.Len(), s.Table.Len())
+	}
+	for _, entry := range s.Table.entries {
+		if result, ok := table.Get(entry); entry != 0 && (!ok || result != Result(entry&1)) {
+			t.Fatalf("entry %x read as %v %v", entry, result, ok)
+		}
+	}
+
+	setConfig(t, "4x4")
+	if _, err := ReadResultTable(bytes.NewReader(file.Bytes())); err == nil || !strings.Contains(err.Error(), "solved for the board") {
+		t.Errorf("error reading the table of another board = %v", err)
+	}
+}
+
+/**
+ * The solved positions are the ground truth of the end of the game: the search that reaches the end everywhere
+ * with every legal action and the score of the positions where the player to move can't play agree with them.
+ */
+func TestSearchAndScoreAgreeWithSolver(t *testing.T) {
+	setConfig(t, "4x4")
+	defer func(full bool) { movegen.AllowNotNeighbor = full }(movegen.AllowNotNeighbor)
+	movegen.AllowNotNeighbor = true
+
+	s := NewSolver()
+	random := rand.New(rand.NewSource(2))
+	solvedSearches := 0
+
+	for i := 0; i < 40; i++ {
+		currentState := movegen.RandomPosition(random)
+		playerId := currentState.PlayerToMove
+		result := s.Solve(&currentState)
+
+		if !movegen.CanPlay(&currentState, playerId) {
+			if score := eval.GetScore(&currentState, playerId, playerId); result != LOSS || score > -1000000/4 {
+				t.Fatalf("the player to move can't play in %s: %v with the score %d", board.FormatPosition(&currentState), result, score)
+			}
+			continue
+		}
+
+		_, score := search.FindBestMove(&currentState, playerId, search.NewTimeManager(time.Now(), search.SearchLimits{MoveTime: 200 * time.Millisecond, Depth: board.GridSize}))
+
+		// the search proves the result with a score beyond the bonus of the end of the game
+		if score > 1000000/4 || score < -1000000/4 {
+			solvedSearches++
+			if (score > 0) != (result == WIN) {
+				t.Fatalf("%s is a %v, the search scores it %d", board.FormatPosition(&currentState), result, score)
+			}
+		}
+	}
+
+	t.Logf("%d positions solved by the search", solvedSearches)
+	if solvedSearches == 0 {
+		t.Error("the search solved no position")
+	}
+}
@@ -0,0 +1,149 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"isola/board"
+)
+
+/**
+ * The results of the solved positions, in an open addressing table indexed by the high bits of board.GetCanonicalHash.
+ * An entry is the hash with its lowest bit replaced by the result, 8 bytes per position, 0 marks an empty slot.
+ */
+type ResultTable struct {
+	entries []uint64
+	count   int
+}
+
+const MIN_TABLE_SIZE = 1 << 10
+
+func NewResultTable() *ResultTable {
+	return &ResultTable{entries: make([]uint64, MIN_TABLE_SIZE)}
+}
+
+func getTableEntry(hash uint64, result Result) uint64 {
+	return hash&^1 | uint64(result)
+}
+
+func (t *ResultTable) getIndex(hash uint64) int {
+	return int(hash>>32) & (len(t.entries) - 1)
+}
+
+func (t *ResultTable) Get(hash uint64) (Result, bool) {
+	for i := t.getIndex(hash); t.entries[i] != 0; i = (i + 1) & (len(t.entries) - 1) {
+		if t.entries[i]&^1 == hash&^1 {
+			return Result(t.entries[i] & 1), true
+		}
+	}
+	return LOSS, false
+}
+
+func (t *ResultTable) Put(hash uint64, result Result) {
+	// at most 3/4 full, so that the probes stay short
+	if 4*(t.count+1) > 3*len(t.entries) {
+		t.grow()
+	}
+
+	i := t.getIndex(hash)
+	for ; t.entries[i] != 0; i = (i + 1) & (len(t.entries) - 1) {
+		if t.entries[i]&^1 == hash&^1 {
+			t.entries[i] = getTableEntry(hash, result)
+			return
+		}
+	}
+
+	t.entries[i] = getTableEntry(hash, result)
+	t.count++
+}
+
+func (t *ResultTable) grow() {
+	previousEntries := t.entries
+	t.entries = make([]uint64, 2*len(previousEntries))
+	t.count = 0
+
+	for _, entry := range previousEntries {
+		if entry != 0 {
+			t.Put(entry, Result(entry&1))
+		}
+	}
+}
+
+func (t *ResultTable) Len() int {
+	return t.count
+}
+
+const TABLE_FILE_HEADER = "isola solver table"
+
+/**
+ * Writes the table to a file: a text header with the board and the rules it was solved for,
+ * then the number of entries and the sorted entries, 8 bytes each in little endian.
+ */
+func (t *ResultTable) Write(w io.Writer) error {
+	entries := make([]uint64, 0, t.count)
+	for _, entry := range t.entries {
+		if entry != 0 {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i] < entries[j] })
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s\n%s\n%s\n", TABLE_FILE_HEADER, board.FormatConfig(board.GetConfig()), board.FormatRules(board.GameRules))
+
+	if err := binary.Write(bw, binary.LittleEndian, uint64(len(entries))); err != nil {
+		return err
+	}
+	if err := binary.Write(bw, binary.LittleEndian, entries); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+/**
+ * Reads a table written by Write, it must have been solved for the current board and rules.
+ */
+func ReadResultTable(r io.Reader) (*ResultTable, error) {
+	br := bufio.NewReader(r)
+
+	var header [3]string
+	for i := range header {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("invalid solver table header: %w", err)
+		}
+		header[i] = strings.TrimSuffix(line, "\n")
+	}
+
+	if header[0] != TABLE_FILE_HEADER {
+		return nil, fmt.Errorf("not a solver table: %q", header[0])
+	}
+	if config := board.FormatConfig(board.GetConfig()); header[1] != config {
+		return nil, fmt.Errorf("the table is solved for the board %q, not %q", header[1], config)
+	}
+	if rules := board.FormatRules(board.GameRules); header[2] != rules {
+		return nil, fmt.Errorf("the table is solved for the rules %q, not %q", header[2], rules)
+	}
+
+	var count uint64
+	if err := binary.Read(br, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+
+	t := NewResultTable()
+	entry := make([]byte, 8)
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(br, entry); err != nil {
+			return nil, fmt.Errorf("entry %d of %d: %w", i, count, err)
+		}
+		value := binary.LittleEndian.Uint64(entry)
+		t.Put(value, Result(value&1))
+	}
+
+	return t, nil
+}